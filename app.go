@@ -2,26 +2,42 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"encoding/base64"
+	"log/slog"
+	gosync "sync"
+	"sync/atomic"
+	"time"
 
 	"ccsync-net/clipboard"
 	"ccsync-net/config"
+	"ccsync-net/hotkey"
+	"ccsync-net/i18n"
+	"ccsync-net/logging"
+	"ccsync-net/service"
 	"ccsync-net/sync"
 
 	wailsRun "github.com/wailsapp/wails/v2/pkg/runtime"
 
 	"github.com/energye/systray"
+	"github.com/skip2/go-qrcode"
 )
 
 // App struct
 type App struct {
 	ctx        context.Context
-	cfg        *config.Config
+	svc        *service.Service
 	server     *sync.Server
 	client     *sync.Client
 	clipboard  *clipboard.Monitor
-	lastCopied string
-	isQuitting bool
+	isQuitting atomic.Bool
+	configErr  error
+
+	// 托盘刷新请求，合并多个事件触发的刷新
+	trayUpdates chan struct{}
+
+	hotkeys       []*hotkey.Hotkey
+	hotkeysConfig *config.Hotkeys // 已注册热键对应的配置
+	hotkeysLock   gosync.Mutex
 }
 
 // NewApp creates a new App application struct
@@ -30,6 +46,8 @@ func NewApp() *App {
 		server:    sync.NewServer(),
 		client:    sync.NewClient(),
 		clipboard: clipboard.NewMonitor(),
+
+		trayUpdates: make(chan struct{}, 1),
 	}
 }
 
@@ -37,208 +55,281 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
-	a.loadConfig()
+	cfg := a.loadConfig()
+	mesh := sync.NewMesh(cfg.DeviceID)
+	a.svc = service.New(cfg, a.server, a.client, mesh, a.clipboard, &wailsEmitter{ctx: ctx, onEvent: a.onEvent})
+
+	// 日志同时显示在界面的日志面板
+	logging.Subscribe(func(e logging.Entry) {
+		wailsRun.EventsEmit(ctx, "log", e)
+	})
 
 	// Start systray
 	go systray.Run(a.onTrayReady, a.onTrayExit)
 
-	a.initCallbacks() // Init callbacks first so logging works during clipboard start
-	a.initClipboard()
-
-	if a.cfg.AutoStart {
-		if a.cfg.Mode == "server" {
-			a.StartServer(a.cfg.ServerPort)
-		} else {
-			a.ConnectToServer(a.cfg.ServerAddress)
-		}
-	}
-}
-
-func (a *App) initClipboard() {
 	// clipboard.Init() already called in main.go
-	// if err := a.clipboard.Init(); err != nil {
-	// 	wailsRun.LogError(a.ctx, "剪贴板初始化失败: "+err.Error())
-	// }
-	a.clipboard.Start()
-}
-
-func (a *App) initCallbacks() {
-	// 剪贴板变化 -> 发送给网络
-	a.clipboard.OnChange = func(content string) {
-		// 防止回环：如果内容与最后一次处理的内容相同，则忽略
-		if content == a.lastCopied {
-			return
-		}
-
-		a.lastCopied = content
-		wailsRun.EventsEmit(a.ctx, "clipboard:local", content)
+	a.svc.Start()
+	go a.updateHotkeys()
+}
 
-		// 如果模式为 receive_only，则不发送
-		if a.cfg.SyncMode == "receive_only" {
-			wailsRun.EventsEmit(a.ctx, "log", "同步模式为只入，跳过发送")
-			return
-		}
+// wailsEmitter 将服务事件转发到 Wails 前端
+type wailsEmitter struct {
+	ctx     context.Context
+	onEvent func(event string, data ...interface{})
+}
 
-		if a.cfg.Mode == "server" && a.server.IsRunning() {
-			a.server.BroadcastClipboard(content, "server")
-		} else if a.cfg.Mode == "client" && a.client.IsConnected() {
-			a.client.SendClipboard(content, "client")
-		}
+func (e *wailsEmitter) Emit(event string, data ...interface{}) {
+	wailsRun.EventsEmit(e.ctx, event, data...)
+	if e.onEvent != nil {
+		e.onEvent(event, data...)
 	}
+}
 
-	// 服务端收到消息 -> 更新本地剪贴板
-	a.server.OnClipboardReceived = func(content string) {
-		// 如果模式为 send_only，则不写入本地剪贴板
-		if a.cfg.SyncMode == "send_only" {
-			// 仍然可以通知界面收到了消息，但不写入
-			wailsRun.EventsEmit(a.ctx, "log", "同步模式为只出，跳过写入本地剪贴板")
-			return
-		}
-
-		if content != a.lastCopied {
-			a.clipboard.SetContent(content)
-			a.lastCopied = content
-			wailsRun.EventsEmit(a.ctx, "clipboard:remote", content)
-		}
+// onEvent 根据服务事件更新托盘状态
+func (a *App) onEvent(event string, data ...interface{}) {
+	switch event {
+	case "server:running", "server:client_count", "server:error",
+		"client:status", "client:active", "client:profile",
+		"mesh:running", "mesh:peer_count",
+		"sync:paused", "config:changed", "clips:recent", "clips:pending", "i18n:changed":
+		a.refreshTray()
 	}
-
-	a.server.OnClientConnected = func(count int) {
-		wailsRun.EventsEmit(a.ctx, "server:client_count", count)
+	if event == "config:changed" {
+		go a.updateHotkeys()
 	}
+}
 
-	a.server.OnClientDisconnected = func(count int) {
-		wailsRun.EventsEmit(a.ctx, "server:client_count", count)
+// loadConfig 加载配置
+func (a *App) loadConfig() *config.Config {
+	cfg, migrated, err := config.Load()
+	if err != nil {
+		slog.Error("app.loadConfigFailed", "err", err)
+		a.configErr = err
 	}
-
-	// 客户端收到消息 -> 更新本地剪贴板
-	a.client.OnClipboardReceived = func(content string) {
-		// 如果模式为 send_only，则不写入本地剪贴板
-		if a.cfg.SyncMode == "send_only" {
-			wailsRun.EventsEmit(a.ctx, "log", "同步模式为只出，跳过写入本地剪贴板")
-			return
-		}
-
-		if content != a.lastCopied {
-			a.clipboard.SetContent(content)
-			a.lastCopied = content
-			wailsRun.EventsEmit(a.ctx, "clipboard:remote", content)
+	if cfg == nil {
+		return config.DefaultConfig()
+	}
+	if migrated {
+		if err := cfg.Save(); err != nil {
+			slog.Error("app.migrateSaveFailed", "err", err)
 		}
 	}
+	return cfg
+}
 
-	a.client.OnConnected = func() {
-		wailsRun.EventsEmit(a.ctx, "client:status", true)
-	}
+// Locale 界面语言及对应的消息模板
+type Locale struct {
+	Locale   string            `json:"locale"`
+	Messages map[string]string `json:"messages"`
+}
 
-	a.client.OnDisconnected = func() {
-		wailsRun.EventsEmit(a.ctx, "client:status", false)
-	}
+// GetLocale 获取当前语言及消息模板，供前端翻译事件中的消息
+func (a *App) GetLocale() Locale {
+	locale := i18n.Locale()
+	return Locale{Locale: locale, Messages: i18n.Messages(locale)}
+}
 
-	// 日志
-	a.server.OnLog = func(msg string) {
-		wailsRun.LogInfo(a.ctx, msg)
-		wailsRun.EventsEmit(a.ctx, "log", msg)
-	}
-	a.client.OnLog = func(msg string) {
-		wailsRun.LogInfo(a.ctx, msg)
-		wailsRun.EventsEmit(a.ctx, "log", msg)
-	}
-	a.clipboard.OnLog = func(msg string) {
-		wailsRun.LogInfo(a.ctx, msg)
-		wailsRun.EventsEmit(a.ctx, "log", msg)
+// GetHotkeyError 获取全局热键不可用的原因，支持时返回空字符串
+func (a *App) GetHotkeyError() string {
+	if err := hotkey.Supported(); err != nil {
+		return err.Error()
 	}
+	return ""
 }
 
-// loadConfig 加载配置
-func (a *App) loadConfig() {
-	cfg, err := config.Load()
-	if err != nil {
-		wailsRun.LogError(a.ctx, "加载配置失败: "+err.Error())
-		a.cfg = config.DefaultConfig()
-	} else {
-		a.cfg = cfg
+// GetConfigError 获取启动时加载配置遇到的错误，无错误时返回空字符串
+func (a *App) GetConfigError() string {
+	if a.configErr == nil {
+		return ""
 	}
+	return a.configErr.Error()
 }
 
-// SaveConfig 保存配置
+// SaveConfig 保存设置页面中的配置。
+// 配置方案与已配对设备由各自的接口修改，这里不覆盖，避免界面中的旧副本丢弃刚导入的方案。
 func (a *App) SaveConfig(cfg config.Config) error {
-	a.cfg.Mode = cfg.Mode
-	a.cfg.ServerPort = cfg.ServerPort
-	a.cfg.ServerAddress = cfg.ServerAddress
-	a.cfg.AutoStart = cfg.AutoStart
-	a.cfg.SyncMode = cfg.SyncMode // 保存 SyncMode
-	return a.cfg.Save()
+	// 仅手动模式依赖全局热键，不支持热键时不允许切换到该模式
+	if cfg.SyncMode == config.SyncManual && a.svc.Config().SyncMode != config.SyncManual {
+		if err := hotkey.Supported(); err != nil {
+			return err
+		}
+	}
+	return a.svc.UpdateConfig(func(c *config.Config) {
+		c.Mode = cfg.Mode
+		c.ServerPort = cfg.ServerPort
+		c.ServerAddress = cfg.ServerAddress
+		c.ServerSecret = cfg.ServerSecret
+		c.ServerTLSPin = cfg.ServerTLSPin
+		c.APIToken = cfg.APIToken
+		c.RequirePairing = cfg.RequirePairing
+		c.BindAddresses = cfg.BindAddresses
+		c.AllowCIDRs = cfg.AllowCIDRs
+		c.DenyCIDRs = cfg.DenyCIDRs
+		c.MaxClients = cfg.MaxClients
+		c.ConnRateLimit = cfg.ConnRateLimit
+		c.MessageRateLimit = cfg.MessageRateLimit
+		c.MeshPeers = cfg.MeshPeers
+		c.DiscoveryPort = cfg.DiscoveryPort
+		c.AutoStart = cfg.AutoStart
+		c.DeviceName = cfg.DeviceName
+		c.SyncMode = cfg.SyncMode // 保存 SyncMode
+		c.SyncConcealed = cfg.SyncConcealed
+		c.ClearAfter = cfg.ClearAfter
+		c.ClearSensitiveAfter = cfg.ClearSensitiveAfter
+		c.SensitivePatterns = cfg.SensitivePatterns
+		c.RestoreAfterClear = cfg.RestoreAfterClear
+		c.NotifyClips = cfg.NotifyClips
+		c.NotifyConnection = cfg.NotifyConnection
+		c.NotifyPeers = cfg.NotifyPeers
+		c.ConfirmReceive = cfg.ConfirmReceive
+		c.AutoAccept = cfg.AutoAccept
+		c.Hotkeys = cfg.Hotkeys
+		c.Debug = cfg.Debug
+		c.LogContent = cfg.LogContent
+		c.Language = cfg.Language
+	})
 }
 
 // GetConfig 获取当前配置
 func (a *App) GetConfig() *config.Config {
-	return a.cfg
+	cfg := a.svc.Config()
+	return &cfg
 }
 
 // StartServer 启动服务端
 func (a *App) StartServer(port int) error {
-	wailsRun.EventsEmit(a.ctx, "status", "正在启动服务端...")
-	err := a.server.Start(port)
-	if err == nil {
-		wailsRun.EventsEmit(a.ctx, "status", fmt.Sprintf("服务端运行中 (端口: %d)", port))
-		wailsRun.EventsEmit(a.ctx, "server:running", true)
-	}
-	return err
+	return a.svc.StartServer(port)
 }
 
 // StopServer 停止服务端
 func (a *App) StopServer() {
-	a.server.Stop()
-	wailsRun.EventsEmit(a.ctx, "status", "服务端已停止")
-	wailsRun.EventsEmit(a.ctx, "server:running", false)
+	a.svc.StopServer()
+}
+
+// GetLocalAddresses 列出本机网络地址，供选择监听地址及告知客户端连接
+func (a *App) GetLocalAddresses() ([]sync.LocalAddress, error) {
+	return sync.LocalAddresses()
+}
+
+// SetPairingMode 开启或关闭配对模式
+func (a *App) SetPairingMode(enabled bool) {
+	a.svc.SetPairing(enabled)
+}
+
+// RespondPairing 批准或拒绝配对请求
+func (a *App) RespondPairing(id string, approve bool) error {
+	return a.svc.RespondPairing(id, approve)
+}
+
+// GetPairedDevices 获取已配对的设备
+func (a *App) GetPairedDevices() []config.PairedDevice {
+	return a.svc.PairedDevices()
+}
+
+// RevokeDevice 撤销设备的配对
+func (a *App) RevokeDevice(id string) error {
+	return a.svc.RevokeDevice(id)
+}
+
+// PairWithServer 向服务端请求配对，成功后保存为配置方案
+func (a *App) PairWithServer(addr, name string) error {
+	return a.svc.PairWith(addr, name)
+}
+
+// ConnectionInfo 供其他设备扫码连接的信息
+type ConnectionInfo struct {
+	URI    string `json:"uri"`
+	QRCode string `json:"qrCode"` // PNG 格式的二维码 data URL
+}
+
+// GetConnectionInfo 生成本机服务端的连接 URI 及其二维码，host 为空时自动选择地址
+func (a *App) GetConnectionInfo(host string) (ConnectionInfo, error) {
+	uri, err := a.svc.ConnectionURI(host)
+	if err != nil {
+		return ConnectionInfo{}, err
+	}
+
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return ConnectionInfo{}, err
+	}
+	return ConnectionInfo{
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// ImportURI 导入 ccsync:// 连接 URI 为配置方案，返回方案名称
+func (a *App) ImportURI(uri string) (string, error) {
+	return a.svc.ImportURI(uri)
+}
+
+// StartMesh 启动 mesh 模式
+func (a *App) StartMesh() error {
+	return a.svc.StartMesh()
+}
+
+// StopMesh 停止 mesh 模式
+func (a *App) StopMesh() {
+	a.svc.StopMesh()
 }
 
 // ConnectToServer 连接服务端
 func (a *App) ConnectToServer(addr string) error {
-	wailsRun.EventsEmit(a.ctx, "status", "正在连接到 "+addr+"...")
-	return a.client.Connect(addr)
+	return a.svc.Connect(addr)
+}
+
+// ConnectProfile 使用配置方案连接，name 为空时按优先级自动选择
+func (a *App) ConnectProfile(name string) error {
+	return a.svc.ConnectProfile(name)
+}
+
+// SetActiveProfile 切换当前使用的配置方案
+func (a *App) SetActiveProfile(name string) error {
+	return a.svc.SetActiveProfile(name)
 }
 
 // Disconnect 断开连接
 func (a *App) Disconnect() {
-	a.client.Disconnect()
-	wailsRun.EventsEmit(a.ctx, "status", "已断开连接")
+	a.svc.Disconnect()
+}
+
+// SetPaused 暂停或恢复发送/接收，minutes 大于 0 时到期自动恢复
+func (a *App) SetPaused(send, receive bool, minutes int) {
+	a.svc.SetPaused(send, receive, time.Duration(minutes)*time.Minute)
+}
+
+// GetPauseState 获取当前暂停状态
+func (a *App) GetPauseState() service.PauseState {
+	return a.svc.Paused()
+}
+
+// GetPendingClips 获取等待确认的内容
+func (a *App) GetPendingClips() []service.PendingClip {
+	return a.svc.PendingClips()
+}
+
+// AcceptClip 接受等待确认的内容，always 为 true 时之后自动接受该发送方的内容
+func (a *App) AcceptClip(id string, always bool) error {
+	return a.svc.AcceptClip(id, always)
+}
+
+// RejectClip 拒绝等待确认的内容
+func (a *App) RejectClip(id string) error {
+	return a.svc.RejectClip(id)
 }
 
 // shutdown 清理资源
 func (a *App) shutdown(ctx context.Context) {
-	a.clipboard.Stop()
-	a.server.Stop()
-	a.client.Disconnect()
+	a.unregisterHotkeys()
+	a.svc.Shutdown()
 }
 
 func (a *App) beforeClose(ctx context.Context) (prevent bool) {
-	if a.isQuitting {
+	if a.isQuitting.Load() {
 		return false
 	}
 	// Default behavior: minimize to tray (hide window)
 	wailsRun.WindowHide(a.ctx)
 	return true
 }
-
-func (a *App) onTrayReady() {
-	systray.SetIcon(iconData)
-	systray.SetTitle("CCSync Net")
-	systray.SetTooltip("CCSync Net")
-
-	mShow := systray.AddMenuItem("显示主窗口", "Show Main Window")
-	mQuit := systray.AddMenuItem("退出", "Quit Application")
-
-	mShow.Click(func() {
-		wailsRun.WindowShow(a.ctx)
-	})
-
-	mQuit.Click(func() {
-		a.isQuitting = true
-		systray.Quit()
-		wailsRun.Quit(a.ctx)
-	})
-}
-
-func (a *App) onTrayExit() {
-	// Cleanup here
-}
@@ -0,0 +1,51 @@
+package clipboard
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// 密码管理器用于标记敏感内容的剪贴板格式
+const (
+	hintKDEPasswordManager    = "x-kde-passwordManagerHint"
+	hintExcludeFromMonitor    = "ExcludeClipboardContentFromMonitorProcessing"
+	hintCanIncludeInHistory   = "CanIncludeInClipboardHistory"
+	hintCanUploadToCloud      = "CanUploadToCloudClipboard"
+	hintNSPasteboardConcealed = "org.nspasteboard.ConcealedType"
+	hintNSPasteboardTransient = "org.nspasteboard.TransientType"
+)
+
+// IsConcealed 检查当前剪贴板是否被密码管理器标记为敏感内容
+func (m *Monitor) IsConcealed() bool {
+	switch runtime.GOOS {
+	case "linux":
+		return concealedLinux()
+	case "windows":
+		return concealedWindows()
+	default:
+		// macOS 等平台暂无可靠的类型查询方式
+		return false
+	}
+}
+
+func concealedLinux() bool {
+	out, err := exec.Command("wl-paste", "--list-types").Output()
+	if err != nil {
+		return false
+	}
+
+	for _, t := range strings.Split(string(out), "\n") {
+		switch strings.TrimSpace(t) {
+		case hintKDEPasswordManager:
+			// KDE 约定值为 "secret"
+			value, err := exec.Command("wl-paste", "--type", hintKDEPasswordManager).Output()
+			if err != nil || strings.TrimSpace(string(value)) == "secret" {
+				return true
+			}
+		case hintExcludeFromMonitor, hintNSPasteboardConcealed, hintNSPasteboardTransient:
+			return true
+		}
+	}
+	return false
+}
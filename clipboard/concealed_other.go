@@ -0,0 +1,7 @@
+//go:build !windows
+
+package clipboard
+
+func concealedWindows() bool {
+	return false
+}
@@ -0,0 +1,76 @@
+package clipboard
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32                         = syscall.NewLazyDLL("user32.dll")
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procRegisterClipboardFormatW   = user32.NewProc("RegisterClipboardFormatW")
+	procIsClipboardFormatAvailable = user32.NewProc("IsClipboardFormatAvailable")
+	procOpenClipboard              = user32.NewProc("OpenClipboard")
+	procCloseClipboard             = user32.NewProc("CloseClipboard")
+	procGetClipboardData           = user32.NewProc("GetClipboardData")
+	procGlobalLock                 = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock               = kernel32.NewProc("GlobalUnlock")
+	procRtlMoveMemory              = kernel32.NewProc("RtlMoveMemory")
+)
+
+func registerFormat(name string) uintptr {
+	p, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return 0
+	}
+	id, _, _ := procRegisterClipboardFormatW.Call(uintptr(unsafe.Pointer(p)))
+	return id
+}
+
+func formatAvailable(id uintptr) bool {
+	if id == 0 {
+		return false
+	}
+	ok, _, _ := procIsClipboardFormatAvailable.Call(id)
+	return ok != 0
+}
+
+// readDWORD 读取剪贴板中某个格式的 DWORD 值
+func readDWORD(id uintptr) (uint32, bool) {
+	if r, _, _ := procOpenClipboard.Call(0); r == 0 {
+		return 0, false
+	}
+	defer procCloseClipboard.Call()
+
+	h, _, _ := procGetClipboardData.Call(id)
+	if h == 0 {
+		return 0, false
+	}
+	ptr, _, _ := procGlobalLock.Call(h)
+	if ptr == 0 {
+		return 0, false
+	}
+	defer procGlobalUnlock.Call(h)
+
+	var v uint32
+	procRtlMoveMemory.Call(uintptr(unsafe.Pointer(&v)), ptr, unsafe.Sizeof(v))
+	return v, true
+}
+
+func concealedWindows() bool {
+	if formatAvailable(registerFormat(hintExcludeFromMonitor)) {
+		return true
+	}
+
+	// 值为 0 表示不允许进入剪贴板历史
+	for _, name := range []string{hintCanIncludeInHistory, hintCanUploadToCloud} {
+		id := registerFormat(name)
+		if !formatAvailable(id) {
+			continue
+		}
+		if v, ok := readDWORD(id); ok && v == 0 {
+			return true
+		}
+	}
+	return false
+}
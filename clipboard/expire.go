@@ -0,0 +1,85 @@
+package clipboard
+
+import (
+	"os/exec"
+	"runtime"
+	"time"
+
+	"golang.design/x/clipboard"
+)
+
+// expiry 待过期的剪贴板内容
+type expiry struct {
+	content  string
+	previous string
+	restore  bool
+	timer    *time.Timer
+}
+
+// SetContentWithExpiry 设置剪贴板内容，并在 ttl 后自动清除。
+// 仅当剪贴板仍为该内容时才清除；restore 为 true 时恢复写入前的内容，否则清空。
+func (m *Monitor) SetContentWithExpiry(content string, ttl time.Duration, restore bool) {
+	m.expiryLock.Lock()
+	previous := cleanContent(m.GetContent())
+	if m.pending != nil {
+		// 连续收到多条过期内容时，恢复到第一条之前的内容
+		m.pending.timer.Stop()
+		previous = m.pending.previous
+	}
+	e := &expiry{
+		content:  cleanContent(content),
+		previous: previous,
+		restore:  restore,
+	}
+	e.timer = time.AfterFunc(ttl, func() { m.expire(e) })
+	m.pending = e
+	m.expiryLock.Unlock()
+
+	m.writeContent(content)
+}
+
+// cancelExpiry 取消待执行的过期清除
+func (m *Monitor) cancelExpiry() {
+	m.expiryLock.Lock()
+	if m.pending != nil {
+		m.pending.timer.Stop()
+		m.pending = nil
+	}
+	m.expiryLock.Unlock()
+}
+
+func (m *Monitor) expire(e *expiry) {
+	m.expiryLock.Lock()
+	if m.pending != e {
+		m.expiryLock.Unlock()
+		return
+	}
+	m.pending = nil
+	m.expiryLock.Unlock()
+
+	// 用户已复制了其他内容，不做处理
+	if cleanContent(m.GetContent()) != e.content {
+		return
+	}
+
+	if e.restore && e.previous != "" {
+		m.writeContent(e.previous)
+		m.logger().Info("clipboard.expiredRestored")
+		return
+	}
+
+	m.clear()
+	m.logger().Info("clipboard.expiredCleared")
+}
+
+// clear 清空剪贴板
+func (m *Monitor) clear() {
+	m.expectSelfWrite("")
+	if runtime.GOOS == "linux" {
+		if err := exec.Command("wl-copy", "--clear").Run(); err != nil {
+			m.logger().Error("clipboard.clearFailed", "err", err)
+		}
+	} else {
+		clipboard.Write(clipboard.FmtText, []byte{})
+	}
+}
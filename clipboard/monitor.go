@@ -3,12 +3,16 @@ package clipboard
 import (
 	"bufio"
 	"context"
-	"fmt"
 	"io"
+	"log/slog"
 	"os/exec"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"ccsync-net/logging"
 
 	"golang.design/x/clipboard"
 )
@@ -18,11 +22,26 @@ type Monitor struct {
 	running     bool
 	runningLock sync.RWMutex
 	cancelFunc  context.CancelFunc
-	lastContent string
-	lastLock    sync.RWMutex
-	// 回调函数
-	OnChange func(content string)
-	OnLog    func(msg string)
+	selfWrite   string
+	selfPending bool
+	selfLock    sync.Mutex
+	pending     *expiry
+	expiryLock  sync.Mutex
+	// 是否同步被密码管理器标记为敏感的内容
+	syncConcealed atomic.Bool
+
+	MonitorHandlers
+}
+
+// MonitorHandlers 剪贴板监听器的回调函数
+type MonitorHandlers struct {
+	OnChange    func(content string, concealed bool)
+	OnConcealed func()
+}
+
+// SetHandlers 设置回调函数，应在 Start 之前调用
+func (m *Monitor) SetHandlers(h MonitorHandlers) {
+	m.MonitorHandlers = h
 }
 
 // NewMonitor 创建剪贴板监听器
@@ -50,7 +69,7 @@ func (m *Monitor) Start() error {
 
 	go m.watchLoop(ctx)
 
-	m.log("Clipboard monitor started")
+	m.logger().Info("clipboard.monitorStarted")
 	return nil
 }
 
@@ -68,7 +87,12 @@ func (m *Monitor) Stop() {
 		m.cancelFunc()
 	}
 
-	m.log("Clipboard monitor stopped")
+	m.logger().Info("clipboard.monitorStopped")
+}
+
+// SetSyncConcealed 设置是否同步被密码管理器标记为敏感的内容
+func (m *Monitor) SetSyncConcealed(v bool) {
+	m.syncConcealed.Store(v)
 }
 
 // IsRunning 检查是否在运行
@@ -80,22 +104,27 @@ func (m *Monitor) IsRunning() bool {
 
 // SetContent 设置剪贴板内容
 func (m *Monitor) SetContent(content string) {
+	m.cancelExpiry()
+	m.writeContent(content)
+}
+
+func (m *Monitor) writeContent(content string) {
+	// 先登记本次写入，监听到对应的变化时忽略，避免回环
+	m.expectSelfWrite(cleanContent(content))
+
 	if runtime.GOOS == "linux" {
 		m.setContentLinux(content)
 	} else {
 		// Fallback to library for other OS
 		clipboard.Write(clipboard.FmtText, []byte(content))
 	}
-
-	// 主动更新 lastContent (存储清理后的版本)
-	m.setLastContent(cleanContent(content))
 }
 
 func (m *Monitor) setContentLinux(content string) {
 	cmd := exec.Command("wl-copy")
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
-		m.log(fmt.Sprintf("Failed to create stdin pipe for wl-copy: %v", err))
+		m.logger().Error("clipboard.pipeFailed", "err", err)
 		return
 	}
 
@@ -105,7 +134,7 @@ func (m *Monitor) setContentLinux(content string) {
 	}()
 
 	if err := cmd.Run(); err != nil {
-		m.log(fmt.Sprintf("wl-copy failed: %v", err))
+		m.logger().Error("clipboard.writeFailed", "err", err)
 	}
 }
 
@@ -132,10 +161,6 @@ func (m *Monitor) watchLoop(ctx context.Context) {
 	}
 	
 	// Non-Linux fallback using library Watch
-	// 初始化
-	initial := m.GetContent()
-	m.setLastContent(cleanContent(initial))
-
 	changed := clipboard.Watch(ctx, clipboard.FmtText)
 	for {
 		select {
@@ -152,21 +177,21 @@ func (m *Monitor) watchLoop(ctx context.Context) {
 }
 
 func (m *Monitor) watchLoopLinux(ctx context.Context) {
-	// Initialize last content
-	m.setLastContent(cleanContent(m.GetContent()))
+	// wl-paste --watch 启动时会针对当前内容触发一次，忽略它
+	m.expectSelfWrite(cleanContent(m.GetContent()))
 
-	m.log("Starting wl-paste --watch monitor...")
+	m.logger().Debug("clipboard.watcherStarting")
 
 	// Use wl-paste --watch to detect changes.
 	cmd := exec.CommandContext(ctx, "wl-paste", "--watch", "echo", "change")
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		m.log(fmt.Sprintf("Failed to start wl-paste watcher: %v", err))
+		m.logger().Error("clipboard.watcherStartFailed", "err", err)
 		return
 	}
 
 	if err := cmd.Start(); err != nil {
-		m.log(fmt.Sprintf("Failed to run wl-paste watcher: %v", err))
+		m.logger().Error("clipboard.watcherFailed", "err", err)
 		return
 	}
 
@@ -195,38 +220,44 @@ func (m *Monitor) watchLoopLinux(ctx context.Context) {
 func (m *Monitor) processChange(current string) {
 	cleaned := cleanContent(current)
 
-	m.lastLock.RLock()
-	lastContent := m.lastContent
-	m.lastLock.RUnlock()
+	// 仅忽略自身写入引起的变化，用户重复复制相同内容仍会同步
+	m.selfLock.Lock()
+	self := m.selfPending && cleaned == m.selfWrite
+	m.selfPending = false
+	m.selfLock.Unlock()
 
-	// 比较清理后的内容，忽略无效空白字符差异
-	if cleaned == lastContent {
+	if self {
 		return
 	}
 
-	m.setLastContent(cleaned)
+	// 密码管理器标记的内容不进入同步
+	concealed := m.IsConcealed()
+	if concealed && !m.syncConcealed.Load() {
+		m.logger().Info("clipboard.concealed")
+		if m.OnConcealed != nil {
+			m.OnConcealed()
+		}
+		return
+	}
 
-	m.log("Content changed detected")
+	m.logger().Debug("clipboard.changed", logging.Content(cleaned))
 
 	// 触发回调，传递清理后的内容，解决多余换行问题
 	if m.OnChange != nil && cleaned != "" {
-		m.OnChange(cleaned)
+		m.OnChange(cleaned, concealed)
 	}
 }
 
-func (m *Monitor) setLastContent(content string) {
-	m.lastLock.Lock()
-	m.lastContent = content
-	m.lastLock.Unlock()
+// expectSelfWrite 登记即将由自身写入的内容
+func (m *Monitor) expectSelfWrite(content string) {
+	m.selfLock.Lock()
+	m.selfWrite = content
+	m.selfPending = true
+	m.selfLock.Unlock()
 }
 
-func (m *Monitor) log(msg string) {
-	// Only log significant events, or if OnLog is set.
-	// User requested to remove useless print content.
-	// We will keep minimal logging.
-	if m.OnLog != nil {
-		m.OnLog(msg)
-	}
+func (m *Monitor) logger() *slog.Logger {
+	return logging.Component("clipboard")
 }
 
 func cleanContent(str string) string {
@@ -234,10 +265,11 @@ func cleanContent(str string) string {
 	return strings.TrimRight(str, "\r\n")
 }
 
-func preview(str string) string {
-	str = cleanContent(str)
-	if len(str) > 20 {
-		return str[:20] + "..."
+// Preview 将内容压缩为一行并截断到 n 个字符，用于菜单、通知等处的预览
+func Preview(str string, n int) string {
+	str = strings.Join(strings.Fields(str), " ")
+	if utf8.RuneCountInString(str) > n {
+		return string([]rune(str)[:n]) + "…"
 	}
 	return str
 }
@@ -0,0 +1,119 @@
+// ccsync-relay 是不依赖图形界面和剪贴板的独立中继服务端，
+// 适合在无界面的常驻主机上通过 systemd 运行。
+//
+// 所有参数均可通过环境变量设置，命令行参数优先：
+//
+//	-port        CCSYNC_PORT        监听端口 (默认 8765)
+//	-bind        CCSYNC_BIND        监听的地址或网卡名称，逗号分隔，为空时监听所有地址
+//	-secret      CCSYNC_SECRET      客户端认证密钥，为空时不校验
+//	-keep-last   CCSYNC_KEEP_LAST   保存各频道最近一条内容，新客户端连接时发送
+//	-api-token   CCSYNC_API_TOKEN   REST API 访问令牌，为空时不启用 /api 接口
+//	-allow       CCSYNC_ALLOW       允许连接的网段，逗号分隔，为空时允许所有
+//	-deny        CCSYNC_DENY        拒绝连接的网段，逗号分隔
+//	-max-clients CCSYNC_MAX_CLIENTS 最大同时连接数，0 表示不限制
+//	-conn-rate   CCSYNC_CONN_RATE   每个 IP 每分钟最多建立的连接数，0 表示不限制
+//	-msg-rate    CCSYNC_MSG_RATE    每个客户端每秒最多发送的消息数，0 表示不限制
+//	-debug       CCSYNC_DEBUG       输出调试级别日志
+//
+// 日志输出到标准错误，由 systemd/journald 收集。
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"ccsync-net/i18n"
+	"ccsync-net/logging"
+	"ccsync-net/sync"
+)
+
+func main() {
+	port := flag.Int("port", envInt("CCSYNC_PORT", 8765), i18n.T("relay.flagPort"))
+	bind := flag.String("bind", os.Getenv("CCSYNC_BIND"), i18n.T("relay.flagBind"))
+	secret := flag.String("secret", os.Getenv("CCSYNC_SECRET"), i18n.T("relay.flagSecret"))
+	keepLast := flag.Bool("keep-last", envBool("CCSYNC_KEEP_LAST", false), i18n.T("relay.flagKeepLast"))
+	apiToken := flag.String("api-token", os.Getenv("CCSYNC_API_TOKEN"), i18n.T("relay.flagAPIToken"))
+	allow := flag.String("allow", os.Getenv("CCSYNC_ALLOW"), i18n.T("relay.flagAllow"))
+	deny := flag.String("deny", os.Getenv("CCSYNC_DENY"), i18n.T("relay.flagDeny"))
+	maxClients := flag.Int("max-clients", envInt("CCSYNC_MAX_CLIENTS", 0), i18n.T("relay.flagMaxClients"))
+	connRate := flag.Int("conn-rate", envInt("CCSYNC_CONN_RATE", 0), i18n.T("relay.flagConnRate"))
+	msgRate := flag.Int("msg-rate", envInt("CCSYNC_MSG_RATE", 0), i18n.T("relay.flagMsgRate"))
+	debug := flag.Bool("debug", envBool("CCSYNC_DEBUG", false), i18n.T("relay.flagDebug"))
+	flag.Parse()
+
+	logging.Init("")
+	logging.SetDebug(*debug)
+	logger := logging.Component("relay")
+
+	server := sync.NewServer()
+	server.SetSecret(*secret)
+	server.SetBindAddresses(splitList(*bind))
+	server.SetKeepLast(*keepLast)
+	server.SetAPIToken(*apiToken)
+	err := server.SetLimits(sync.Limits{
+		Allow:         splitList(*allow),
+		Deny:          splitList(*deny),
+		MaxClients:    *maxClients,
+		ConnPerMinute: *connRate,
+		MsgPerSecond:  *msgRate,
+	})
+	if err != nil {
+		logger.Error("relay.invalidLimits", "err", err)
+		os.Exit(1)
+	}
+
+	if *secret == "" {
+		logger.Warn("relay.noSecret")
+	}
+
+	// 运行中监听失败时退出，交由 systemd 重启
+	failed := make(chan error, 1)
+	server.OnError = func(err error) {
+		failed <- err
+	}
+
+	if err := server.Start(*port); err != nil {
+		logger.Error("relay.startFailed", "err", err)
+		os.Exit(1)
+	}
+
+	// 等待退出信号
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case <-sig:
+		server.Stop()
+	case err := <-failed:
+		logger.Error("server.failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func envInt(name string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return v
+	}
+	return def
+}
+
+func envBool(name string, def bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(name)); err == nil {
+		return v
+	}
+	return def
+}
@@ -1,40 +1,236 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
+
+	"ccsync-net/i18n"
+	"ccsync-net/logging"
+)
+
+// 运行模式
+const (
+	ModeServer = "server"
+	ModeClient = "client"
+	ModeHybrid = "hybrid" // 同时运行服务端与上行客户端，在两者之间桥接
+	ModeMesh   = "mesh"   // 网状网络，每个节点既接受连接也主动连接其他节点
+)
+
+// 同步模式
+const (
+	SyncBidirectional = "bidirectional"
+	SyncSendOnly      = "send_only"
+	SyncReceiveOnly   = "receive_only"
+	SyncDisabled      = "disabled"
+	SyncManual        = "manual" // 只在按下热键时发送或写入
 )
 
 // Config 应用配置
 type Config struct {
-	// 模式: "server" 或 "client"
+	// 配置文件格式版本
+	Version int `json:"version"`
+
+	// 本机设备 ID，用于标识剪贴板内容的来源
+	DeviceID string `json:"deviceId"`
+
+	// 本机设备名称，配对时显示给对方
+	DeviceName string `json:"deviceName"`
+
+	// 模式: "server"、"client"、"hybrid" 或 "mesh"
 	Mode string `json:"mode"`
 
 	// 服务端配置
 	ServerPort int `json:"serverPort"`
 
+	// 服务端监听的地址或网卡名称 (如 "127.0.0.1"、"::1"、"eth0")，为空时监听所有地址
+	BindAddresses []string `json:"bindAddresses"`
+
+	// 允许连接的网段 (CIDR 或 IP)，为空时允许所有
+	AllowCIDRs []string `json:"allowCidrs"`
+
+	// 拒绝连接的网段 (CIDR 或 IP)，优先于 AllowCIDRs
+	DenyCIDRs []string `json:"denyCidrs"`
+
+	// 最大同时连接的客户端数，0 表示不限制
+	MaxClients int `json:"maxClients"`
+
+	// 每个 IP 每分钟最多建立的连接数，0 表示不限制
+	ConnRateLimit int `json:"connRateLimit"`
+
+	// 每个客户端每秒最多发送的消息数，0 表示不限制
+	MessageRateLimit int `json:"messageRateLimit"`
+
+	// 服务端认证密钥，为空时不校验
+	ServerSecret string `json:"serverSecret"`
+
+	// 服务端前置 TLS 代理的证书 SHA-256 指纹，生成连接 URI 时附带
+	ServerTLSPin string `json:"serverTlsPin"`
+
+	// REST API 访问令牌，为空时不启用 /api 接口
+	APIToken string `json:"apiToken"`
+
+	// 已通过配对授权的设备
+	PairedDevices []PairedDevice `json:"pairedDevices"`
+
+	// 只允许已配对设备连接。未设置服务端密钥时，关闭此项则任何客户端都可以连接
+	RequirePairing bool `json:"requirePairing"`
+
 	// 客户端配置
 	ServerAddress string `json:"serverAddress"`
 
+	// 已保存的服务端配置方案，按优先级排列
+	Profiles []Profile `json:"profiles"`
+
+	// 当前使用的配置方案名称，为空时使用 serverAddress；
+	// serverAddress 也为空时按优先级自动选择可连接的方案
+	ActiveProfile string `json:"activeProfile"`
+
+	// mesh 模式下手动指定的节点地址 (host:port)
+	MeshPeers []string `json:"meshPeers"`
+
+	// mesh 模式局域网发现使用的 UDP 端口，0 表示不进行自动发现
+	DiscoveryPort int `json:"discoveryPort"`
+
 	// 是否自动启动
 	AutoStart bool `json:"autoStart"`
 
-	// 同步模式: "bidirectional", "send_only", "receive_only"
+	// 同步模式: "bidirectional", "send_only", "receive_only", "disabled", "manual"
 	SyncMode string `json:"syncMode"`
+
+	// 是否同步密码管理器标记为敏感的内容（默认跳过）
+	SyncConcealed bool `json:"syncConcealed"`
+
+	// 收到的远程内容在 N 秒后自动清除，0 表示不清除
+	ClearAfter int `json:"clearAfter"`
+
+	// 敏感内容（发送方标记或匹配 SensitivePatterns）在 N 秒后自动清除，0 表示不清除
+	ClearSensitiveAfter int `json:"clearSensitiveAfter"`
+
+	// 判定敏感内容的正则表达式
+	SensitivePatterns []string `json:"sensitivePatterns"`
+
+	// 清除时恢复之前的剪贴板内容，否则清空
+	RestoreAfterClear bool `json:"restoreAfterClear"`
+
+	// 收到的远程内容先等待确认，接受后才写入本地剪贴板
+	ConfirmReceive bool `json:"confirmReceive"`
+
+	// 无需确认、直接写入的已配对设备 ID，只对服务端 (本机或上游) 通过设备凭据认证的发送方生效
+	AutoAccept []string `json:"autoAccept"`
+
+	// 全局热键
+	Hotkeys Hotkeys `json:"hotkeys"`
+
+	// 收到远程内容时显示桌面通知
+	NotifyClips bool `json:"notifyClips"`
+
+	// 与服务端的连接断开或恢复、服务端异常停止时显示桌面通知
+	NotifyConnection bool `json:"notifyConnection"`
+
+	// 有新设备连接到本机服务端或发现新的 mesh 节点时显示桌面通知
+	NotifyPeers bool `json:"notifyPeers"`
+
+	// 输出调试级别日志
+	Debug bool `json:"debug"`
+
+	// 调试日志中记录剪贴板内容原文，仅在 Debug 开启时生效
+	LogContent bool `json:"logContent"`
+
+	// 界面与日志语言，如 "zh-CN"、"en"，为空时跟随系统
+	Language string `json:"language"`
+}
+
+// Hotkeys 全局热键设置，如 "Ctrl+Alt+C"，为空表示不注册
+type Hotkeys struct {
+	Push  string `json:"push"`  // 将当前剪贴板发送给其他设备，不受同步模式限制
+	Pull  string `json:"pull"`  // 将最近收到的内容写入本地剪贴板
+	Pause string `json:"pause"` // 暂停或恢复同步
+}
+
+// PairedDevice 已配对的设备
+type PairedDevice struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	TokenHash string `json:"tokenHash"` // 设备凭据的 SHA-256，不保存明文
+	PairedAt  int64  `json:"pairedAt"`  // 配对时间 (毫秒时间戳)
+}
+
+// Clone 返回配置的深拷贝
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.SensitivePatterns = slices.Clone(c.SensitivePatterns)
+	clone.AutoAccept = slices.Clone(c.AutoAccept)
+	clone.Profiles = slices.Clone(c.Profiles)
+	clone.BindAddresses = slices.Clone(c.BindAddresses)
+	clone.MeshPeers = slices.Clone(c.MeshPeers)
+	clone.AllowCIDRs = slices.Clone(c.AllowCIDRs)
+	clone.DenyCIDRs = slices.Clone(c.DenyCIDRs)
+	clone.PairedDevices = slices.Clone(c.PairedDevices)
+	return &clone
+}
+
+// RunsServer 当前模式是否运行服务端
+func (c *Config) RunsServer() bool {
+	return c.Mode == ModeServer || c.Mode == ModeHybrid
+}
+
+// RunsClient 当前模式是否运行客户端
+func (c *Config) RunsClient() bool {
+	return c.Mode == ModeClient || c.Mode == ModeHybrid
+}
+
+// Profile 服务端配置方案
+type Profile struct {
+	Name    string `json:"name"`    // 名称，如 "办公室"、"家"
+	Address string `json:"address"` // host:port
+	Secret  string `json:"secret"`  // 认证密钥
+	TLSPin  string `json:"tlsPin"`  // 服务端证书 SHA-256 指纹
+	Channel string `json:"channel"` // 频道
+}
+
+// FindProfile 按名称查找配置方案
+func (c *Config) FindProfile(name string) (Profile, bool) {
+	for _, p := range c.Profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Profile{}, false
 }
 
 // DefaultConfig 默认配置
 func DefaultConfig() *Config {
 	return &Config{
-		Mode:          "server",
+		Version:       CurrentVersion,
+		DeviceID:      newDeviceID(),
+		DeviceName:    hostname(),
+		Mode:          ModeServer,
 		ServerPort:    8765,
-		ServerAddress: "127.0.0.1:8765",
+		ServerAddress: defaultServerAddress,
 		AutoStart:     false,
-		SyncMode:      "bidirectional",
+		SyncMode:      SyncBidirectional,
+		DiscoveryPort: 8766,
+
+		ClearSensitiveAfter: 30,
+		RestoreAfterClear:   true,
 	}
 }
 
+// 配置中包含密钥与设备凭据，文件与目录只允许当前用户访问
+const (
+	fileMode os.FileMode = 0600
+	dirMode  os.FileMode = 0700
+)
+
+// defaultServerAddress 默认连接的服务端地址
+const defaultServerAddress = "127.0.0.1:8765"
+
 // configPath 获取配置文件路径
 func configPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -42,37 +238,91 @@ func configPath() (string, error) {
 		return "", err
 	}
 	configDir := filepath.Join(homeDir, ".ccsync-net")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(configDir, dirMode); err != nil {
 		return "", err
 	}
 	return filepath.Join(configDir, "config.json"), nil
 }
 
-// Load 加载配置
-func Load() (*Config, error) {
+// Load 加载配置。
+// 文件无法解析时会备份原文件并返回默认配置；配置不合法时返回配置及校验错误。
+// 旧版本的配置只在内存中升级，migrated 为 true 时由调用方保存。
+func Load() (cfg *Config, migrated bool, err error) {
 	path, err := configPath()
 	if err != nil {
-		return DefaultConfig(), nil
+		return DefaultConfig(), false, nil
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return DefaultConfig(), nil
+			return DefaultConfig(), false, nil
 		}
-		return nil, err
+		return nil, false, err
 	}
+	return loadData(path, data)
+}
+
+// loadData 解析已读取的配置文件内容，无法解析时备份原文件并返回默认配置
+func loadData(path string, data []byte) (cfg *Config, migrated bool, err error) {
+	cfg, migrated, err = parse(data)
+	if cfg == nil {
+		backup := fmt.Sprintf("%s.%s.bak", path, time.Now().Format("20060102-150405"))
+		if bErr := os.WriteFile(backup, data, fileMode); bErr != nil {
+			return DefaultConfig(), false, i18n.Errorf("config.parseFailedNoBackup", "err", err, "backupErr", bErr)
+		}
+		logging.Component("config").Warn("config.parseFailedDefault", "backup", backup, "err", err)
+		return DefaultConfig(), false, i18n.Errorf("config.parseFailedBackup", "backup", backup, "err", err)
+	}
+	return cfg, migrated, err
+}
+
+// parse 解析配置文件内容并升级到当前版本，无法解析时返回 nil
+func parse(data []byte) (*Config, bool, error) {
+
+	// 在默认配置上解析，旧文件缺少的字段保留默认值
+	cfg := DefaultConfig()
+	cfg.Version = 0
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, false, err
+	}
+
+	// 更新版本的配置可以读取，但 Save 会拒绝覆盖，避免丢失不认识的字段
+	if cfg.Version > CurrentVersion {
+		return cfg, false, i18n.Errorf("config.versionTooNew", "version", cfg.Version, "supported", CurrentVersion)
+	}
+
+	migrated := cfg.Version < CurrentVersion
+	if migrated {
+		logging.Component("config").Info("config.migrating", "from", cfg.Version, "to", CurrentVersion)
+		migrate(cfg)
+	}
+
+	return cfg, migrated, cfg.Validate()
+}
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return DefaultConfig(), nil
+// hostname 获取主机名作为默认设备名称
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "ccsync-net"
 	}
+	return name
+}
 
-	return &cfg, nil
+// newDeviceID 生成随机设备 ID
+func newDeviceID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
 
-// Save 保存配置
+// Save 保存配置。配置来自更新版本的程序时拒绝保存。
 func (c *Config) Save() error {
+	if c.Version > CurrentVersion {
+		return i18n.Errorf("config.saveVersionTooNew", "version", c.Version, "supported", CurrentVersion)
+	}
+
 	path, err := configPath()
 	if err != nil {
 		return err
@@ -83,5 +333,45 @@ func (c *Config) Save() error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	if err := writeFileAtomic(path, data, fileMode); err != nil {
+		return err
+	}
+	rememberWrite(data)
+	restrictPermissions(path)
+	return nil
+}
+
+// restrictPermissions 收紧旧版本以宽松权限创建的配置目录与备份文件
+func restrictPermissions(path string) {
+	os.Chmod(filepath.Dir(path), dirMode)
+	backups, _ := filepath.Glob(path + ".*.bak")
+	for _, b := range backups {
+		os.Chmod(b, fileMode)
+	}
+}
+
+// writeFileAtomic 先写入临时文件再重命名，避免写入中断导致配置损坏
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
 }
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// useTempHome 将配置目录指向临时目录，返回配置文件路径
+func useTempHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	path, err := configPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       Config
+		mode     string
+		syncMode string
+		profiles []Profile
+	}{
+		{
+			name:     "v0 missing modes",
+			in:       Config{ServerAddress: "10.0.0.1:8765"},
+			mode:     ModeServer,
+			syncMode: SyncBidirectional,
+			profiles: []Profile{{Name: "default", Address: "10.0.0.1:8765"}},
+		},
+		{
+			name:     "v0 keeps modes",
+			in:       Config{Mode: ModeClient, SyncMode: SyncSendOnly},
+			mode:     ModeClient,
+			syncMode: SyncSendOnly,
+		},
+		{
+			name:     "v1 address becomes profile",
+			in:       Config{Version: 1, Mode: ModeClient, SyncMode: SyncBidirectional, ServerAddress: "host:1"},
+			mode:     ModeClient,
+			syncMode: SyncBidirectional,
+			profiles: []Profile{{Name: "default", Address: "host:1"}},
+		},
+		{
+			name:     "v1 default address stays",
+			in:       Config{Version: 1, Mode: ModeClient, SyncMode: SyncBidirectional, ServerAddress: defaultServerAddress},
+			mode:     ModeClient,
+			syncMode: SyncBidirectional,
+		},
+		{
+			name:     "v1 keeps profiles",
+			in:       Config{Version: 1, Mode: ModeClient, SyncMode: SyncBidirectional, ServerAddress: "host:1", Profiles: []Profile{{Name: "work", Address: "host:2"}}},
+			mode:     ModeClient,
+			syncMode: SyncBidirectional,
+			profiles: []Profile{{Name: "work", Address: "host:2"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.in
+			migrate(&cfg)
+			if cfg.Version != CurrentVersion {
+				t.Errorf("Version = %d, want %d", cfg.Version, CurrentVersion)
+			}
+			if cfg.Mode != tt.mode || cfg.SyncMode != tt.syncMode {
+				t.Errorf("Mode, SyncMode = %q, %q, want %q, %q", cfg.Mode, cfg.SyncMode, tt.mode, tt.syncMode)
+			}
+			if cfg.ServerAddress != tt.in.ServerAddress {
+				t.Errorf("ServerAddress = %q, want %q", cfg.ServerAddress, tt.in.ServerAddress)
+			}
+			if len(cfg.Profiles) != len(tt.profiles) {
+				t.Fatalf("Profiles = %+v, want %+v", cfg.Profiles, tt.profiles)
+			}
+			for i, p := range cfg.Profiles {
+				if p != tt.profiles[i] {
+					t.Errorf("Profiles[%d] = %+v, want %+v", i, p, tt.profiles[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMigratesInMemory(t *testing.T) {
+	path := useTempHome(t)
+	old := []byte(`{"deviceId":"abc","mode":"client","serverPort":8765,"serverAddress":"10.0.0.1:8765"}`)
+	if err := os.WriteFile(path, old, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, migrated, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !migrated || cfg.Version != CurrentVersion || cfg.DeviceID != "abc" || len(cfg.Profiles) != 1 {
+		t.Fatalf("Load = %+v, migrated %v", cfg, migrated)
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(old) {
+		t.Fatal("Load rewrote the config file")
+	}
+
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, migrated, err := Load(); migrated || err != nil {
+		t.Fatalf("reload: migrated %v, err %v", migrated, err)
+	}
+}
+
+func TestLoadVersionTooNew(t *testing.T) {
+	path := useTempHome(t)
+	newer := []byte(`{"version":99,"deviceId":"abc","mode":"server","serverPort":8765,"futureField":true}`)
+	if err := os.WriteFile(path, newer, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, migrated, err := Load()
+	if err == nil || migrated || cfg == nil || cfg.DeviceID != "abc" {
+		t.Fatalf("Load = %+v, %v, %v", cfg, migrated, err)
+	}
+	if err := cfg.Save(); err == nil {
+		t.Fatal("Save of newer config succeeded")
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(newer) {
+		t.Fatal("newer config file was overwritten")
+	}
+}
+
+func TestLoadInvalidJSON(t *testing.T) {
+	path := useTempHome(t)
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _, err := Load()
+	if err == nil || cfg == nil || cfg.Version != CurrentVersion {
+		t.Fatalf("Load = %+v, %v", cfg, err)
+	}
+	backups, _ := filepath.Glob(path + ".*.bak")
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v", backups)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	for _, data := range []string{"first", "second"} {
+		if err := writeFileAtomic(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil || string(got) != data {
+			t.Fatalf("ReadFile = %q, %v, want %q", got, err, data)
+		}
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil || info.Mode().Perm() != 0600 {
+			t.Fatalf("mode = %v, %v", info.Mode().Perm(), err)
+		}
+	}
+
+	// 临时文件已清理
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("dir entries = %d, want 1", len(entries))
+	}
+
+	// 目录不存在时返回错误
+	if err := writeFileAtomic(filepath.Join(dir, "missing", "config.json"), []byte("x"), 0600); err == nil {
+		t.Fatal("write into missing dir succeeded")
+	}
+}
+
+func TestSaveRestrictsPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	path := useTempHome(t)
+	dir := filepath.Dir(path)
+	backup := path + ".20240101-000000.bak"
+	// 旧版本创建的目录与文件
+	os.Chmod(dir, 0755)
+	os.WriteFile(path, []byte("{}"), 0644)
+	os.WriteFile(backup, []byte("{"), 0644)
+
+	if err := DefaultConfig().Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]os.FileMode{dir: dirMode, path: fileMode, backup: fileMode} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("%s: mode = %v, want %v", filepath.Base(name), info.Mode().Perm(), want)
+		}
+	}
+}
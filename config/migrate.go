@@ -0,0 +1,34 @@
+package config
+
+// CurrentVersion 当前配置文件格式版本
+//
+// 版本历史:
+//   - 0: 无 version 字段的初始格式
+//   - 1: 增加 deviceId、敏感内容相关设置及 version 字段
+//   - 2: 增加服务端配置方案 profiles，修改过的 serverAddress 同时保存为方案
+const CurrentVersion = 2
+
+// migratedProfileName 由 serverAddress 生成的配置方案名称，写入文件，不随界面语言变化
+const migratedProfileName = "default"
+
+// migrate 将旧版本配置升级到当前版本
+func migrate(c *Config) {
+	if c.Version < 1 {
+		// 初始格式可能缺少以下字段
+		if c.Mode == "" {
+			c.Mode = ModeServer
+		}
+		if c.SyncMode == "" {
+			c.SyncMode = SyncBidirectional
+		}
+		c.Version = 1
+	}
+
+	if c.Version < 2 {
+		// 默认地址不生成方案；serverAddress 保留，未选择方案时仍然使用
+		if len(c.Profiles) == 0 && c.ServerAddress != "" && c.ServerAddress != defaultServerAddress {
+			c.Profiles = []Profile{{Name: migratedProfileName, Address: c.ServerAddress}}
+		}
+		c.Version = 2
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"ccsync-net/hotkey"
+	"ccsync-net/i18n"
+)
+
+// Validate 校验配置，返回所有不合法字段组成的错误
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Mode {
+	case ModeServer, ModeClient, ModeHybrid, ModeMesh:
+	default:
+		errs = append(errs, fieldError("mode", "config.unknownMode", "value", c.Mode))
+	}
+
+	switch c.SyncMode {
+	case SyncBidirectional, SyncSendOnly, SyncReceiveOnly, SyncDisabled, SyncManual:
+	default:
+		errs = append(errs, fieldError("syncMode", "config.unknownSyncMode", "value", c.SyncMode))
+	}
+
+	if c.ServerPort < 1 || c.ServerPort > 65535 {
+		errs = append(errs, fieldError("serverPort", "config.portRange", "port", c.ServerPort, "min", 1))
+	}
+
+	if c.ServerAddress != "" {
+		if _, _, err := net.SplitHostPort(c.ServerAddress); err != nil {
+			errs = append(errs, fieldError("serverAddress", "config.invalidAddress", "value", c.ServerAddress))
+		}
+	}
+
+	for i, b := range c.BindAddresses {
+		if strings.TrimSpace(b) == "" {
+			errs = append(errs, fieldError(fmt.Sprintf("bindAddresses[%d]", i), "config.empty"))
+		}
+	}
+
+	for _, list := range []struct {
+		name  string
+		items []string
+	}{{"allowCidrs", c.AllowCIDRs}, {"denyCidrs", c.DenyCIDRs}} {
+		for i, item := range list.items {
+			if net.ParseIP(item) != nil {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(item); err != nil {
+				errs = append(errs, fieldError(fmt.Sprintf("%s[%d]", list.name, i), "config.invalidCIDR", "value", item))
+			}
+		}
+	}
+
+	if c.MaxClients < 0 {
+		errs = append(errs, fieldError("maxClients", "config.negative"))
+	}
+	if c.ConnRateLimit < 0 {
+		errs = append(errs, fieldError("connRateLimit", "config.negative"))
+	}
+	if c.MessageRateLimit < 0 {
+		errs = append(errs, fieldError("messageRateLimit", "config.negative"))
+	}
+
+	if c.DiscoveryPort < 0 || c.DiscoveryPort > 65535 {
+		errs = append(errs, fieldError("discoveryPort", "config.portRange", "port", c.DiscoveryPort, "min", 0))
+	}
+	for i, addr := range c.MeshPeers {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			errs = append(errs, fieldError(fmt.Sprintf("meshPeers[%d]", i), "config.invalidAddress", "value", addr))
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, p := range c.Profiles {
+		if p.Name == "" {
+			errs = append(errs, fieldError(fmt.Sprintf("profiles[%d]", i), "config.emptyName"))
+		} else if names[p.Name] {
+			errs = append(errs, fieldError(fmt.Sprintf("profiles[%d]", i), "config.duplicateName", "value", p.Name))
+		}
+		names[p.Name] = true
+
+		addr := p.Address
+		if i := strings.Index(addr, "://"); i >= 0 {
+			addr = addr[i+3:]
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			errs = append(errs, fieldError(fmt.Sprintf("profiles[%d]", i), "config.invalidAddress", "value", p.Address))
+		}
+	}
+	if c.ActiveProfile != "" && !names[c.ActiveProfile] {
+		errs = append(errs, fieldError("activeProfile", "config.profileNotFound", "value", c.ActiveProfile))
+	}
+
+	if c.ClearAfter < 0 {
+		errs = append(errs, fieldError("clearAfter", "config.negative"))
+	}
+	if c.ClearSensitiveAfter < 0 {
+		errs = append(errs, fieldError("clearSensitiveAfter", "config.negative"))
+	}
+
+	for _, p := range c.SensitivePatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			errs = append(errs, fieldError("sensitivePatterns", "config.invalidPattern", "value", p, "err", err))
+		}
+	}
+
+	for i, sender := range c.AutoAccept {
+		if strings.TrimSpace(sender) == "" {
+			errs = append(errs, fieldError(fmt.Sprintf("autoAccept[%d]", i), "config.empty"))
+		}
+	}
+
+	hotkeys := make(map[string]bool)
+	for _, hk := range []struct{ name, value string }{
+		{"hotkeys.push", c.Hotkeys.Push},
+		{"hotkeys.pull", c.Hotkeys.Pull},
+		{"hotkeys.pause", c.Hotkeys.Pause},
+	} {
+		if hk.value == "" {
+			continue
+		}
+		sc, err := hotkey.Parse(hk.value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hk.name, err))
+			continue
+		}
+		if hotkeys[sc.String()] {
+			errs = append(errs, fieldError(hk.name, "config.duplicateHotkey", "value", hk.value))
+		}
+		hotkeys[sc.String()] = true
+	}
+
+	if c.Language != "" && i18n.Normalize(c.Language) == "" {
+		errs = append(errs, fieldError("language", "config.unknownLanguage", "value", c.Language))
+	}
+
+	return errors.Join(errs...)
+}
+
+// fieldError 返回带字段名前缀的可翻译错误
+func fieldError(field, key string, args ...any) error {
+	return fmt.Errorf("%s: %w", field, i18n.Errorf(key, args...))
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(c *Config)
+		field  string // 错误信息应包含的字段名，为空表示配置合法
+	}{
+		{name: "default", update: func(c *Config) {}},
+		{name: "mode", update: func(c *Config) { c.Mode = "p2p" }, field: "mode"},
+		{name: "sync mode", update: func(c *Config) { c.SyncMode = "x" }, field: "syncMode"},
+		{name: "manual sync mode", update: func(c *Config) { c.SyncMode = SyncManual }},
+		{name: "port", update: func(c *Config) { c.ServerPort = 0 }, field: "serverPort"},
+		{name: "server address", update: func(c *Config) { c.ServerAddress = "host" }, field: "serverAddress"},
+		{name: "bind address", update: func(c *Config) { c.BindAddresses = []string{" "} }, field: "bindAddresses[0]"},
+		{name: "cidr", update: func(c *Config) { c.AllowCIDRs = []string{"10.0.0.0/8", "10.0.0.0/33"} }, field: "allowCidrs[1]"},
+		{name: "deny ip", update: func(c *Config) { c.DenyCIDRs = []string{"10.0.0.1"} }},
+		{name: "max clients", update: func(c *Config) { c.MaxClients = -1 }, field: "maxClients"},
+		{name: "discovery port", update: func(c *Config) { c.DiscoveryPort = 70000 }, field: "discoveryPort"},
+		{name: "mesh peer", update: func(c *Config) { c.MeshPeers = []string{"peer"} }, field: "meshPeers[0]"},
+		{name: "profile name", update: func(c *Config) { c.Profiles = []Profile{{Address: "h:1"}} }, field: "profiles[0]"},
+		{name: "duplicate profile", update: func(c *Config) {
+			c.Profiles = []Profile{{Name: "a", Address: "h:1"}, {Name: "a", Address: "h:2"}}
+		}, field: "profiles[1]"},
+		{name: "profile scheme", update: func(c *Config) { c.Profiles = []Profile{{Name: "a", Address: "wss://h:1"}} }},
+		{name: "active profile", update: func(c *Config) { c.ActiveProfile = "missing" }, field: "activeProfile"},
+		{name: "clear after", update: func(c *Config) { c.ClearAfter = -1 }, field: "clearAfter"},
+		{name: "pattern", update: func(c *Config) { c.SensitivePatterns = []string{"("} }, field: "sensitivePatterns"},
+		{name: "auto accept", update: func(c *Config) { c.AutoAccept = []string{""} }, field: "autoAccept[0]"},
+		{name: "hotkey", update: func(c *Config) { c.Hotkeys.Push = "V" }, field: "hotkeys.push"},
+		{name: "duplicate hotkey", update: func(c *Config) {
+			c.Hotkeys.Push, c.Hotkeys.Pull = "Ctrl+Alt+V", "alt+ctrl+v"
+		}, field: "hotkeys.pull"},
+		{name: "language", update: func(c *Config) { c.Language = "xx" }, field: "language"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.update(cfg)
+			err := cfg.Validate()
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("Validate = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.field+": ") {
+				t.Fatalf("Validate = %v, want error for %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestValidateJoinsErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Mode = "x"
+	cfg.ServerPort = -1
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "mode: ") || !strings.Contains(err.Error(), "serverPort: ") {
+		t.Fatalf("Validate = %v", err)
+	}
+}
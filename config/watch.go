@@ -0,0 +1,133 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"ccsync-net/logging"
+)
+
+// Change 配置项变更
+type Change struct {
+	Field string      `json:"field"` // JSON 字段名
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Field, c.Old, c.New)
+}
+
+// Diff 比较两份配置，返回发生变化的字段
+func Diff(old, new *Config) []Change {
+	var changes []Change
+
+	ov := reflect.ValueOf(old).Elem()
+	nv := reflect.ValueOf(new).Elem()
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		fa, fb := ov.Field(i), nv.Field(i)
+		a, b := fa.Interface(), fb.Interface()
+		if reflect.DeepEqual(a, b) {
+			continue
+		}
+		// nil 与空列表视为相同
+		if fa.Kind() == reflect.Slice && fa.Len() == 0 && fb.Len() == 0 {
+			continue
+		}
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		changes = append(changes, Change{Field: name, Old: a, New: b})
+	}
+	return changes
+}
+
+// Has 检查变更列表中是否包含指定字段
+func Has(changes []Change, field string) bool {
+	for _, c := range changes {
+		if c.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ownWritesSize 记录本进程最近写入的配置内容的条数
+const ownWritesSize = 8
+
+// ownWrites 本进程最近通过 Save 写入的配置内容哈希。
+// Watch 读到其中任一内容时不回调，避免把自身较早的保存当作外部修改，覆盖之后的更新。
+var ownWrites struct {
+	hashes [][sha256.Size]byte
+	lock   sync.Mutex
+}
+
+func rememberWrite(data []byte) {
+	sum := sha256.Sum256(data)
+	ownWrites.lock.Lock()
+	ownWrites.hashes = append(ownWrites.hashes, sum)
+	if len(ownWrites.hashes) > ownWritesSize {
+		ownWrites.hashes = ownWrites.hashes[1:]
+	}
+	ownWrites.lock.Unlock()
+}
+
+func isOwnWrite(data []byte) bool {
+	sum := sha256.Sum256(data)
+	ownWrites.lock.Lock()
+	defer ownWrites.lock.Unlock()
+	return slices.Contains(ownWrites.hashes, sum)
+}
+
+// Watch 轮询配置文件，文件被其他程序修改时重新加载并回调，本进程自身的保存不回调。
+// 阻塞直到 ctx 取消。
+func Watch(ctx context.Context, interval time.Duration, onChange func(cfg *Config, err error)) {
+	path, err := configPath()
+	if err != nil {
+		logging.Component("config").Warn("config.watchFailed", "err", err)
+		return
+	}
+
+	modTime := fileModTime(path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mt := fileModTime(path)
+			if mt.Equal(modTime) {
+				continue
+			}
+			modTime = mt
+
+			data, err := os.ReadFile(path)
+			if err != nil || isOwnWrite(data) {
+				continue
+			}
+			logging.Component("config").Debug("config.modified", "path", path)
+			cfg, _, err := loadData(path, data)
+			onChange(cfg, err)
+		}
+	}
+}
+
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatchIgnoresOwnWrites(t *testing.T) {
+	path := useTempHome(t)
+	if err := DefaultConfig().Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := make(chan *Config, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go Watch(ctx, 10*time.Millisecond, func(cfg *Config, err error) { changed <- cfg })
+	time.Sleep(50 * time.Millisecond)
+
+	// 本进程的保存，包括较早保存的内容，都不回调
+	older := DefaultConfig()
+	older.DeviceName = "older"
+	newer := older.Clone()
+	newer.DeviceName = "newer"
+	for i, cfg := range []*Config{older, newer, older} {
+		if err := cfg.Save(); err != nil {
+			t.Fatal(err)
+		}
+		touch(t, path, i+1)
+		time.Sleep(50 * time.Millisecond)
+	}
+	select {
+	case cfg := <-changed:
+		t.Fatalf("callback for own write: %s", cfg.DeviceName)
+	default:
+	}
+
+	// 其他程序的修改
+	data, _ := os.ReadFile(path)
+	edited := []byte(string(data[:len(data)-1]) + " \n}")
+	if err := os.WriteFile(path, edited, 0600); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, path, 10)
+	select {
+	case cfg := <-changed:
+		if cfg == nil || cfg.DeviceName != "older" {
+			t.Errorf("reloaded %+v", cfg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("external edit not reported")
+	}
+}
+
+// touch 将修改时间设为不同的值，避免文件系统时间精度导致修改被忽略
+func touch(t *testing.T, path string, n int) {
+	t.Helper()
+	mt := time.Now().Add(time.Duration(n) * time.Second)
+	if err := os.Chtimes(path, mt, mt); err != nil {
+		t.Fatal(err)
+	}
+}
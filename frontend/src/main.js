@@ -6,6 +6,12 @@ let isServerRunning = false;
 let isClientIntentRunning = false;
 // Client actual connection status (true/false)
 let isClientConnected = false;
+// 最近一次从后端加载的完整配置，保存时在其基础上修改界面上的字段
+let loadedConfig = {};
+// 后端消息模板，用于翻译事件与日志中带 key 的消息
+let messages = {};
+// 全局热键不可用的原因，为空表示可用。不可用时不能选择仅手动模式
+let hotkeyError = '';
 
 window.onload = async () => {
     // 绑定 JS 函数到全局以便 HTML 调用
@@ -13,15 +19,36 @@ window.onload = async () => {
     window.toggleServer = toggleServer;
     window.toggleClient = toggleClient;
     window.saveConfig = saveConfig;
+    window.selectProfile = selectProfile;
+    window.togglePairing = togglePairing;
+    window.pairWithServer = pairWithServer;
+    window.toggleQRCode = toggleQRCode;
+    window.importURI = importURI;
+    window.revokeDevice = revokeDevice;
+    window.acceptClip = acceptClip;
+    window.rejectClip = rejectClip;
     window.clearLogs = clearLogs;
+    window.filterLogs = filterLogs;
 
     // 初始化事件监听
     setupEvents();
+    await loadMessages();
     
     // 加载配置
     try {
+        hotkeyError = await window.go.main.App.GetHotkeyError();
+        const hint = document.getElementById('hotkeyHint');
+        hint.innerText = hotkeyError;
+        hint.style.display = hotkeyError ? '' : 'none';
+
         const cfg = await window.go.main.App.GetConfig();
         loadConfigToUI(cfg);
+        loadLocalAddresses();
+        renderPendingClips(await window.go.main.App.GetPendingClips());
+        const cfgErr = await window.go.main.App.GetConfigError();
+        if (cfgErr) {
+            log("配置文件有误: " + cfgErr);
+        }
     } catch (e) {
         log("加载配置失败: " + e);
     }
@@ -31,12 +58,36 @@ function setupEvents() {
     // 监听运行时事件
     window.runtime.EventsOn("log", log);
     window.runtime.EventsOn("status", updateStatus);
+    window.runtime.EventsOn("i18n:changed", loadMessages);
     
     window.runtime.EventsOn("server:running", (running) => {
         isServerRunning = running;
         updateServerUI(running);
     });
 
+    window.runtime.EventsOn("pair:mode", (enabled) => {
+        document.getElementById('pairingMode').checked = enabled;
+    });
+
+    window.runtime.EventsOn("pair:request", async (req) => {
+        const approve = confirm(`设备 "${req.deviceName}" (${req.addr}) 请求配对\n\n校验码: ${req.code}\n\n请确认对方显示的校验码一致后再批准。`);
+        try {
+            await window.go.main.App.RespondPairing(req.id, approve);
+        } catch (e) {
+            log("处理配对请求失败: " + e);
+        }
+    });
+
+    window.runtime.EventsOn("clips:pending", renderPendingClips);
+
+    window.runtime.EventsOn("pair:code", (code) => {
+        log(`配对校验码: ${code}，请在服务端核对后批准`);
+    });
+
+    window.runtime.EventsOn("server:error", (msg) => {
+        log("服务端错误: " + msg);
+    });
+
     window.runtime.EventsOn("client:status", (connected) => {
         isClientConnected = connected;
         // 如果连接成功，确保意图状态也为运行中（防止状态不一致）
@@ -46,30 +97,75 @@ function setupEvents() {
         updateClientUI(isClientIntentRunning, connected);
     });
 
+    window.runtime.EventsOn("client:active", (active) => {
+        // 客户端也可能通过托盘启动或停止
+        isClientIntentRunning = active;
+        updateClientUI(active, isClientConnected);
+    });
+
+    window.runtime.EventsOn("mesh:peer_count", (count) => {
+        log(`网状模式已连接节点数: ${count}`);
+    });
+
+    window.runtime.EventsOn("client:profile", (name) => {
+        if (name) {
+            log(`已连接到配置方案: ${name}`);
+        }
+    });
+
     window.runtime.EventsOn("server:client_count", (count) => {
         document.getElementById('clientCount').innerText = count;
     });
 
-    window.runtime.EventsOn("clipboard:local", (content) => {
-        log(`本地复制: ${preview(content)}`);
+    window.runtime.EventsOn("sync:paused", (state) => {
+        const badge = document.getElementById('appStatus');
+        badge.classList.toggle('paused', state.send || state.receive);
+    });
+
+    window.runtime.EventsOn("config:changed", (cfg) => {
+        // 配置在其他地方被修改 (如手动编辑配置文件)，刷新界面
+        loadConfigToUI(cfg);
+    });
+
+    window.runtime.EventsOn("config:error", (msg) => {
+        log("配置校验失败: " + msg);
     });
 
-    window.runtime.EventsOn("clipboard:remote", (content) => {
-        log(`收到同步: ${preview(content)}`);
+    window.runtime.EventsOn("clipboard:concealed", () => {
+        log("检测到密码管理器标记的敏感内容，已跳过同步");
     });
 }
 
 function loadConfigToUI(cfg) {
+    loadedConfig = cfg;
     document.getElementById('serverPort').value = cfg.serverPort;
     document.getElementById('serverAddr').value = cfg.serverAddress;
     document.getElementById('autoStart').checked = cfg.autoStart;
+    document.getElementById('debugLog').checked = cfg.debug;
+    document.getElementById('logContent').checked = cfg.logContent;
+    document.getElementById('notifyClips').checked = cfg.notifyClips;
+    document.getElementById('notifyConnection').checked = cfg.notifyConnection;
+    document.getElementById('notifyPeers').checked = cfg.notifyPeers;
+    document.getElementById('language').value = cfg.language || '';
+    document.getElementById('bindAddrs').value = (cfg.bindAddresses || []).join(', ');
+    document.getElementById('confirmReceive').checked = cfg.confirmReceive;
+    document.getElementById('autoAccept').value = (cfg.autoAccept || []).join(', ');
+    document.getElementById('apiToken').value = cfg.apiToken || '';
+    document.getElementById('requirePairing').checked = cfg.requirePairing;
+    loadProfilesToUI(cfg);
+    loadDevicesToUI(cfg);
     
     // 加载同步模式
     const syncMode = cfg.syncMode || 'bidirectional';
     const cbSend = document.getElementById('cbSend');
     const cbReceive = document.getElementById('cbReceive');
+    const cbManual = document.getElementById('cbManual');
 
     if (cbSend && cbReceive) {
+        cbManual.checked = syncMode === 'manual' && !hotkeyError;
+        cbManual.disabled = !!hotkeyError;
+        cbSend.disabled = cbManual.checked;
+        cbReceive.disabled = cbManual.checked;
         if (syncMode === 'bidirectional') {
             cbSend.checked = true;
             cbReceive.checked = true;
@@ -80,24 +176,221 @@ function loadConfigToUI(cfg) {
             cbSend.checked = false;
             cbReceive.checked = true;
         } else {
-            // disabled or unknown
+            // disabled, manual or unknown
             cbSend.checked = false;
             cbReceive.checked = false;
         }
     }
 
-    if (cfg.mode) {
-        switchMode(cfg.mode);
+    const hotkeys = cfg.hotkeys || {};
+    document.getElementById('hotkeyPush').value = hotkeys.push || '';
+    document.getElementById('hotkeyPull').value = hotkeys.pull || '';
+    document.getElementById('hotkeyPause').value = hotkeys.pause || '';
+
+    // 只更新界面，不重复保存
+    if (cfg.mode && cfg.mode !== currentMode) {
+        showMode(cfg.mode);
+    }
+}
+
+function loadDevicesToUI(cfg) {
+    // 已有配对设备但未启用认证时提示
+    const open = !cfg.serverSecret && !cfg.requirePairing;
+    document.getElementById('pairingHint').style.display =
+        open && (cfg.pairedDevices || []).length > 0 ? '' : 'none';
+
+    const container = document.getElementById('pairedDevices');
+    container.innerHTML = '';
+    (cfg.pairedDevices || []).forEach(d => {
+        const item = document.createElement('div');
+        item.className = 'stat-item';
+
+        const name = document.createElement('span');
+        name.className = 'label';
+        name.innerText = d.name || d.id;
+
+        const btn = document.createElement('button');
+        btn.className = 'btn-text';
+        btn.innerText = '撤销';
+        btn.onclick = () => revokeDevice(d.id, d.name);
+
+        item.appendChild(name);
+        item.appendChild(btn);
+        container.appendChild(item);
+    });
+}
+
+// renderPendingClips 显示等待确认的内容，最新的在前
+function renderPendingClips(clips) {
+    clips = clips || [];
+    const container = document.getElementById('pendingClips');
+    container.innerHTML = '';
+    document.getElementById('pendingCard').style.display = clips.length ? '' : 'none';
+    clips.forEach(c => {
+        const item = document.createElement('div');
+        item.className = 'stat-item';
+
+        const text = document.createElement('span');
+        text.className = 'label';
+        const preview = c.sensitive ? '（敏感内容）' : c.content.replace(/\s+/g, ' ').slice(0, 60);
+        text.innerText = `${c.sender}: ${preview}`;
+        text.title = c.sensitive ? '' : c.content;
+
+        // 只有经过认证的已配对设备可以设为总是接受
+        const buttons = [['接受', () => acceptClip(c.id, false)]];
+        if (c.deviceId) {
+            buttons.push(['总是接受', () => acceptClip(c.id, true)]);
+        }
+        buttons.push(['拒绝', () => rejectClip(c.id)]);
+
+        const actions = document.createElement('span');
+        buttons.forEach(([label, onclick]) => {
+            const btn = document.createElement('button');
+            btn.className = 'btn-text';
+            btn.innerText = label;
+            btn.onclick = onclick;
+            actions.appendChild(btn);
+        });
+
+        item.appendChild(text);
+        item.appendChild(actions);
+        container.appendChild(item);
+    });
+}
+
+async function acceptClip(id, always) {
+    try {
+        await window.go.main.App.AcceptClip(id, always);
+    } catch (e) {
+        log("接受内容失败: " + e);
+    }
+}
+
+async function rejectClip(id) {
+    try {
+        await window.go.main.App.RejectClip(id);
+    } catch (e) {
+        log("拒绝内容失败: " + e);
+    }
+}
+
+async function togglePairing() {
+    await window.go.main.App.SetPairingMode(document.getElementById('pairingMode').checked);
+}
+
+async function revokeDevice(id, name) {
+    if (!confirm(`确定撤销设备 "${name || id}" 的配对吗？`)) return;
+    try {
+        await window.go.main.App.RevokeDevice(id);
+    } catch (e) {
+        log("撤销配对失败: " + e);
+    }
+}
+
+async function pairWithServer() {
+    const addr = document.getElementById('serverAddr').value;
+    if (!addr) return alert("请输入服务端地址");
+    const name = prompt("为该服务端命名 (用于配置方案)", addr);
+    if (name === null) return;
+
+    try {
+        await window.go.main.App.PairWithServer(addr, name);
+        log("配对成功");
+    } catch (e) {
+        log("配对失败: " + e);
+    }
+}
+
+async function toggleQRCode() {
+    const card = document.getElementById('qrCard');
+    if (card.style.display !== 'none') {
+        card.style.display = 'none';
+        return;
+    }
+
+    try {
+        const info = await window.go.main.App.GetConnectionInfo("");
+        document.getElementById('qrImage').src = info.qrCode;
+        document.getElementById('qrUri').innerText = info.uri;
+        card.style.display = '';
+    } catch (e) {
+        log("生成连接二维码失败: " + e);
+    }
+}
+
+async function importURI() {
+    const uri = prompt("粘贴 ccsync:// 连接地址");
+    if (!uri) return;
+
+    try {
+        const name = await window.go.main.App.ImportURI(uri);
+        log(`已导入配置方案 ${name}`);
+    } catch (e) {
+        log("导入失败: " + e);
+    }
+}
+
+async function loadLocalAddresses() {
+    try {
+        const addrs = await window.go.main.App.GetLocalAddresses();
+        const list = (addrs || [])
+            .filter(a => !a.loopback)
+            .map(a => `${a.ip} (${a.interface})`);
+        document.getElementById('localAddrs').innerText = list.length > 0 ? list.join(', ') : '-';
+    } catch (e) {
+        log("获取本机地址失败: " + e);
+    }
+}
+
+function loadProfilesToUI(cfg) {
+    const profiles = cfg.profiles || [];
+    const group = document.getElementById('profileGroup');
+    const select = document.getElementById('profileSelect');
+
+    group.style.display = profiles.length > 0 ? '' : 'none';
+    select.innerHTML = '';
+
+    const manual = document.createElement('option');
+    manual.value = '';
+    manual.innerText = '不使用方案 (使用服务端地址)';
+    select.appendChild(manual);
+
+    profiles.forEach(p => {
+        const opt = document.createElement('option');
+        opt.value = p.name;
+        opt.innerText = `${p.name} (${p.address})`;
+        select.appendChild(opt);
+    });
+    select.value = cfg.activeProfile || '';
+}
+
+async function selectProfile() {
+    const name = document.getElementById('profileSelect').value;
+    const profile = (loadedConfig.profiles || []).find(p => p.name === name);
+    if (profile) {
+        document.getElementById('serverAddr').value = profile.address;
+    }
+    try {
+        await window.go.main.App.SetActiveProfile(name);
+        loadedConfig.activeProfile = name;
+    } catch (e) {
+        log("切换配置方案失败: " + e);
     }
 }
 
 async function saveConfig() {
     const cbSend = document.getElementById('cbSend');
     const cbReceive = document.getElementById('cbReceive');
+    const cbManual = document.getElementById('cbManual');
     
     let syncMode = 'bidirectional';
     if (cbSend && cbReceive) {
-        if (cbSend.checked && cbReceive.checked) {
+        cbSend.disabled = cbManual.checked;
+        cbReceive.disabled = cbManual.checked;
+        if (cbManual.checked) {
+            // 仅手动：只在按下热键时发送或写入
+            syncMode = 'manual';
+        } else if (cbSend.checked && cbReceive.checked) {
             syncMode = 'bidirectional';
         } else if (cbSend.checked && !cbReceive.checked) {
             syncMode = 'send_only';
@@ -113,28 +406,58 @@ async function saveConfig() {
     }
 
     const cfg = {
+        ...loadedConfig,
         mode: currentMode,
         serverPort: parseInt(document.getElementById('serverPort').value),
         serverAddress: document.getElementById('serverAddr').value,
+        bindAddresses: document.getElementById('bindAddrs').value
+            .split(',').map(s => s.trim()).filter(s => s),
+        apiToken: document.getElementById('apiToken').value.trim(),
+        requirePairing: document.getElementById('requirePairing').checked,
         autoStart: document.getElementById('autoStart').checked,
+        debug: document.getElementById('debugLog').checked,
+        logContent: document.getElementById('logContent').checked,
+        notifyClips: document.getElementById('notifyClips').checked,
+        notifyConnection: document.getElementById('notifyConnection').checked,
+        notifyPeers: document.getElementById('notifyPeers').checked,
+        confirmReceive: document.getElementById('confirmReceive').checked,
+        autoAccept: document.getElementById('autoAccept').value
+            .split(',').map(s => s.trim()).filter(s => s),
+        hotkeys: {
+            push: document.getElementById('hotkeyPush').value.trim(),
+            pull: document.getElementById('hotkeyPull').value.trim(),
+            pause: document.getElementById('hotkeyPause').value.trim(),
+        },
+        language: document.getElementById('language').value,
         syncMode: syncMode
     };
     
-    await window.go.main.App.SaveConfig(cfg);
-    log("配置已保存");
+    try {
+        await window.go.main.App.SaveConfig(cfg);
+        loadedConfig = cfg;
+        log("配置已保存");
+    } catch (e) {
+        log("保存配置失败: " + e);
+    }
 }
 
 function switchMode(mode) {
-    if (currentMode === 'server' && isServerRunning) {
+    if (currentMode !== 'client' && isServerRunning) {
         alert("请先停止服务端后再切换模式");
         return;
     }
     // 客户端模式下，允许随时切换，但最好提示一下
-    if (currentMode === 'client' && isClientIntentRunning) {
+    if ((currentMode === 'client' || currentMode === 'hybrid') && isClientIntentRunning) {
         alert("请先断开连接后再切换模式");
         return;
     }
 
+    showMode(mode);
+    saveConfig();
+}
+
+// showMode 切换模式按钮与面板
+function showMode(mode) {
     currentMode = mode;
     
     // UI 切换
@@ -142,23 +465,34 @@ function switchMode(mode) {
         btn.classList.toggle('active', btn.dataset.mode === mode);
     });
     
-    document.getElementById('serverPanel').classList.toggle('active', mode === 'server');
-    document.getElementById('clientPanel').classList.toggle('active', mode === 'client');
-    
-    saveConfig();
+    // 桥接模式同时显示服务端与客户端面板，网状模式使用服务端面板
+    document.getElementById('serverPanel').classList.toggle('active', mode !== 'client');
+    document.getElementById('clientPanel').classList.toggle('active', mode === 'client' || mode === 'hybrid');
 }
 
 async function toggleServer() {
     const btn = document.getElementById('serverToggleBtn');
     
     if (isServerRunning) {
-        await window.go.main.App.StopServer();
+        if (currentMode === 'mesh') {
+            await window.go.main.App.StopMesh();
+        } else {
+            await window.go.main.App.StopServer();
+        }
     } else {
         const port = parseInt(document.getElementById('serverPort').value);
         if (!port) return alert("请输入有效端口");
         
         await saveConfig(); // 启动前保存配置
-        await window.go.main.App.StartServer(port);
+        try {
+            if (currentMode === 'mesh') {
+                await window.go.main.App.StartMesh();
+            } else {
+                await window.go.main.App.StartServer(port);
+            }
+        } catch (e) {
+            alert("服务端启动失败: " + e);
+        }
     }
 }
 
@@ -180,7 +514,12 @@ async function toggleClient() {
         updateClientUI(true, false); // 意图为运行，连接状态暂时未知/连接中
 
         await saveConfig(); // 启动前保存配置
-        await window.go.main.App.ConnectToServer(addr);
+        const profile = document.getElementById('profileSelect').value;
+        if (profile) {
+            await window.go.main.App.ConnectProfile(profile);
+        } else {
+            await window.go.main.App.ConnectToServer(addr);
+        }
     }
 }
 
@@ -241,26 +580,77 @@ function updateClientUI(intentRunning, connected) {
 }
 
 function updateStatus(msg) {
-    log(msg);
+    log(translate(msg));
 }
 
+// loadMessages 加载当前语言的消息模板
+async function loadMessages() {
+    try {
+        const locale = await window.go.main.App.GetLocale();
+        messages = locale.messages || {};
+        document.documentElement.lang = locale.locale;
+    } catch (e) {
+        messages = {};
+    }
+}
+
+// t 翻译消息 key，{name} 由 params 中的同名参数替换，没有模板时返回 fallback
+function t(key, params, fallback) {
+    const tmpl = messages[key];
+    if (tmpl === undefined) {
+        return fallback !== undefined ? fallback : key;
+    }
+    return tmpl.replace(/\{(\w+)\}/g, (m, name) =>
+        params && params[name] !== undefined ? String(params[name]) : m);
+}
+
+// translate 将后端发送的消息 ({key, params, text}) 转换为文本，字符串原样返回
+function translate(msg) {
+    if (msg && typeof msg === 'object' && msg.key) {
+        return t(msg.key, msg.params, msg.text);
+    }
+    return msg;
+}
+
+const LOG_LEVELS = ['debug', 'info', 'warn', 'error'];
+
+// log 显示一条日志，msg 为界面自身的提示文本或后端发送的日志记录
 function log(msg) {
+    const record = typeof msg === 'string'
+        ? { time: Date.now(), level: 'info', message: msg }
+        : msg;
+
     const logs = document.getElementById('logs');
     const entry = document.createElement('div');
-    entry.className = 'log-entry';
-    
-    const time = new Date().toLocaleTimeString();
-    entry.innerHTML = `<span class="log-time">[${time}]</span> ${msg}`;
-    
+    entry.className = `log-entry log-${record.level}`;
+    entry.dataset.level = record.level;
+
+    const time = document.createElement('span');
+    time.className = 'log-time';
+    time.innerText = `[${new Date(record.time).toLocaleTimeString()}]`;
+    entry.appendChild(time);
+
+    const attrs = Object.entries(record.attrs || {}).map(([k, v]) => `${k}=${v}`).join(' ');
+    const message = record.key ? t(record.key, null, record.message) : record.message;
+    entry.appendChild(document.createTextNode(`${message}${attrs ? ' ' + attrs : ''}`));
+    entry.title = record.component || '';
+    entry.hidden = !levelVisible(record.level);
+
     logs.appendChild(entry);
     logs.scrollTop = logs.scrollHeight;
 }
 
-function clearLogs() {
-    document.getElementById('logs').innerHTML = '';
+function levelVisible(level) {
+    const min = document.getElementById('logLevel').value;
+    return LOG_LEVELS.indexOf(level) >= LOG_LEVELS.indexOf(min);
+}
+
+function filterLogs() {
+    document.querySelectorAll('#logs .log-entry').forEach(entry => {
+        entry.hidden = !levelVisible(entry.dataset.level);
+    });
 }
 
-function preview(str) {
-    if (!str) return "";
-    return str.length > 20 ? str.substring(0, 20) + "..." : str;
+function clearLogs() {
+    document.getElementById('logs').innerHTML = '';
 }
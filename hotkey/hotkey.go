@@ -0,0 +1,151 @@
+// Package hotkey 注册系统全局热键。
+//
+// 热键写作 "Ctrl+Alt+V" 的形式：若干修饰键 (Ctrl、Shift、Alt、Super) 加一个按键，
+// 不区分大小写。Linux 上通过 X11 抓取按键，Wayland 下只在 XWayland 窗口获得焦点时有效，
+// 没有 XWayland (未设置 DISPLAY) 时不支持；Windows 与 macOS 使用系统的热键接口。
+package hotkey
+
+import (
+	"strings"
+	"time"
+
+	"ccsync-net/i18n"
+)
+
+// repeatInterval 两次触发的最小间隔，按住不放时的自动重复只算一次
+const repeatInterval = 300 * time.Millisecond
+
+// ErrUnsupported 当前平台不支持全局热键
+var ErrUnsupported = i18n.Errorf("hotkey.unsupported")
+
+// 修饰键，按此顺序显示
+var modifierNames = []string{"Ctrl", "Shift", "Alt", "Super"}
+
+// modifierAliases 修饰键的其他写法
+var modifierAliases = map[string]string{
+	"ctrl":    "Ctrl",
+	"control": "Ctrl",
+	"shift":   "Shift",
+	"alt":     "Alt",
+	"option":  "Alt",
+	"super":   "Super",
+	"win":     "Super",
+	"cmd":     "Super",
+	"command": "Super",
+	"meta":    "Super",
+}
+
+// keyNames 支持的按键，字母与数字之外的部分
+var keyNames = []string{
+	"F1", "F2", "F3", "F4", "F5", "F6", "F7", "F8", "F9", "F10", "F11", "F12",
+	"Space", "Enter", "Esc", "Tab", "Delete", "Left", "Right", "Up", "Down",
+}
+
+// keyAliases 按键的其他写法
+var keyAliases = map[string]string{
+	"return": "Enter",
+	"escape": "Esc",
+	"del":    "Delete",
+}
+
+// Shortcut 解析后的热键
+type Shortcut struct {
+	Mods []string // 修饰键，已按 modifierNames 的顺序排列
+	Key  string   // 按键名称，如 "V"、"5"、"F1"、"Space"
+}
+
+// String 返回规范写法，如 "Ctrl+Alt+V"
+func (sc Shortcut) String() string {
+	return strings.Join(append(append([]string(nil), sc.Mods...), sc.Key), "+")
+}
+
+// Parse 解析热键，至少需要一个修饰键
+func Parse(s string) (Shortcut, error) {
+	parts := strings.Split(s, "+")
+	if len(parts) < 2 {
+		return Shortcut{}, i18n.Errorf("hotkey.noModifier", "value", s)
+	}
+
+	var sc Shortcut
+	mods := make(map[string]bool)
+	for _, p := range parts[:len(parts)-1] {
+		mod, ok := modifierAliases[strings.ToLower(strings.TrimSpace(p))]
+		if !ok {
+			return Shortcut{}, i18n.Errorf("hotkey.unknownModifier", "value", strings.TrimSpace(p))
+		}
+		mods[mod] = true
+	}
+	for _, mod := range modifierNames {
+		if mods[mod] {
+			sc.Mods = append(sc.Mods, mod)
+		}
+	}
+
+	key, ok := parseKey(strings.TrimSpace(parts[len(parts)-1]))
+	if !ok {
+		return Shortcut{}, i18n.Errorf("hotkey.unknownKey", "value", strings.TrimSpace(parts[len(parts)-1]))
+	}
+	sc.Key = key
+	return sc, nil
+}
+
+// parseKey 返回按键的规范名称
+func parseKey(s string) (string, bool) {
+	if len(s) == 1 {
+		c := strings.ToUpper(s)[0]
+		if c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			return string(c), true
+		}
+		return "", false
+	}
+	if key, ok := keyAliases[strings.ToLower(s)]; ok {
+		return key, true
+	}
+	for _, key := range keyNames {
+		if strings.EqualFold(s, key) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// Hotkey 已注册的全局热键
+type Hotkey struct {
+	Shortcut Shortcut
+
+	fn   func()
+	last time.Time
+	platformHotkey
+}
+
+// Supported 检查当前环境是否支持全局热键，不支持时返回 ErrUnsupported
+func Supported() error {
+	return supported()
+}
+
+// Register 注册全局热键，按下时调用 fn。当前环境不支持时返回 ErrUnsupported。
+func Register(sc Shortcut, fn func()) (*Hotkey, error) {
+	if err := supported(); err != nil {
+		return nil, err
+	}
+	h := &Hotkey{Shortcut: sc, fn: fn}
+	if err := h.register(); err != nil {
+		return nil, i18n.Errorf("hotkey.registerFailed", "hotkey", sc, "err", err)
+	}
+	return h, nil
+}
+
+// Unregister 注销热键
+func (h *Hotkey) Unregister() {
+	h.unregister()
+}
+
+// fire 处理一次按下事件，由各平台的事件循环调用
+func (h *Hotkey) fire() {
+	now := time.Now()
+	repeated := now.Sub(h.last) < repeatInterval
+	h.last = now
+	if !repeated {
+		h.fn()
+	}
+}
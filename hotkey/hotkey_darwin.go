@@ -0,0 +1,15 @@
+//go:build cgo
+
+package hotkey
+
+import (
+	xhotkey "golang.design/x/hotkey"
+)
+
+// nativeModifiers 修饰键对应的系统掩码，Alt 即 Option，Super 即 Command
+var nativeModifiers = map[string]xhotkey.Modifier{
+	"Ctrl":  xhotkey.ModCtrl,
+	"Shift": xhotkey.ModShift,
+	"Alt":   xhotkey.ModOption,
+	"Super": xhotkey.ModCmd,
+}
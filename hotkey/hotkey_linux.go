@@ -0,0 +1,118 @@
+package hotkey
+
+import (
+	"errors"
+	"os"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// x11Modifiers 修饰键对应的 X11 掩码
+var x11Modifiers = map[string]uint16{
+	"Ctrl":  xproto.ModMaskControl,
+	"Shift": xproto.ModMaskShift,
+	"Alt":   xproto.ModMask1,
+	"Super": xproto.ModMask4,
+}
+
+// x11Keysyms 非字母数字按键对应的 keysym，字母与数字的 keysym 即其小写 ASCII 码
+var x11Keysyms = map[string]xproto.Keysym{
+	"F1": 0xffbe, "F2": 0xffbf, "F3": 0xffc0, "F4": 0xffc1, "F5": 0xffc2, "F6": 0xffc3,
+	"F7": 0xffc4, "F8": 0xffc5, "F9": 0xffc6, "F10": 0xffc7, "F11": 0xffc8, "F12": 0xffc9,
+	"Space": 0x0020, "Enter": 0xff0d, "Esc": 0xff1b, "Tab": 0xff09, "Delete": 0xffff,
+	"Left": 0xff51, "Up": 0xff52, "Right": 0xff53, "Down": 0xff54,
+}
+
+// x11LockMasks 抓取时需要同时覆盖的锁定键状态：无、CapsLock、NumLock、两者
+var x11LockMasks = []uint16{0, xproto.ModMaskLock, xproto.ModMask2, xproto.ModMaskLock | xproto.ModMask2}
+
+// platformHotkey 每个热键使用独立的 X11 连接，关闭连接即释放抓取
+type platformHotkey struct {
+	conn *xgb.Conn
+}
+
+// supported 热键通过 X11 抓取，只支持 X11 会话。
+// Wayland 会话即使有 XWayland，也只有 X11 窗口获得焦点时才能收到按键，视为不支持。
+func supported() error {
+	if os.Getenv("DISPLAY") == "" || os.Getenv("WAYLAND_DISPLAY") != "" {
+		return ErrUnsupported
+	}
+	return nil
+}
+
+func (h *Hotkey) register() error {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return err
+	}
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+
+	keycode, err := x11Keycode(conn, h.Shortcut.Key)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	var mods uint16
+	for _, m := range h.Shortcut.Mods {
+		mods |= x11Modifiers[m]
+	}
+	for _, lock := range x11LockMasks {
+		err := xproto.GrabKeyChecked(conn, true, root, mods|lock, keycode,
+			xproto.GrabModeAsync, xproto.GrabModeAsync).Check()
+		if err != nil {
+			// 通常是热键已被其他程序占用
+			conn.Close()
+			return err
+		}
+	}
+
+	h.conn = conn
+	go h.loop(keycode)
+	return nil
+}
+
+func (h *Hotkey) unregister() {
+	h.conn.Close()
+}
+
+// loop 读取按键事件直到连接关闭
+func (h *Hotkey) loop(keycode xproto.Keycode) {
+	for {
+		ev, err := h.conn.WaitForEvent()
+		if ev == nil && err == nil {
+			return
+		}
+		if kp, ok := ev.(xproto.KeyPressEvent); ok && kp.Detail == keycode {
+			h.fire()
+		}
+	}
+}
+
+// x11Keycode 查找产生指定按键的 keycode
+func x11Keycode(conn *xgb.Conn, key string) (xproto.Keycode, error) {
+	sym, ok := x11Keysyms[key]
+	if !ok {
+		c := key[0]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		sym = xproto.Keysym(c)
+	}
+
+	setup := xproto.Setup(conn)
+	first, count := setup.MinKeycode, int(setup.MaxKeycode-setup.MinKeycode)+1
+	mapping, err := xproto.GetKeyboardMapping(conn, first, byte(count)).Reply()
+	if err != nil {
+		return 0, err
+	}
+	per := int(mapping.KeysymsPerKeycode)
+	for i := 0; i < count; i++ {
+		for j := 0; j < per; j++ {
+			if mapping.Keysyms[i*per+j] == sym {
+				return first + xproto.Keycode(i), nil
+			}
+		}
+	}
+	return 0, errors.New("no keycode for " + key)
+}
@@ -0,0 +1,37 @@
+package hotkey
+
+import "testing"
+
+func TestSupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		display string
+		wayland string
+		want    error
+	}{
+		{name: "x11", display: ":0", want: nil},
+		{name: "xwayland", display: ":0", wayland: "wayland-0", want: ErrUnsupported},
+		{name: "wayland only", wayland: "wayland-0", want: ErrUnsupported},
+		{name: "no display", want: ErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Setenv("DISPLAY", tt.display)
+		t.Setenv("WAYLAND_DISPLAY", tt.wayland)
+		if err := Supported(); err != tt.want {
+			t.Errorf("%s: Supported = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRegisterUnsupported(t *testing.T) {
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	t.Setenv("DISPLAY", ":0")
+
+	sc, err := Parse("Ctrl+Alt+V")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Register(sc, func() {}); err != ErrUnsupported {
+		t.Fatalf("Register = %v, want ErrUnsupported", err)
+	}
+}
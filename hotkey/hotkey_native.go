@@ -0,0 +1,70 @@
+//go:build windows || (darwin && cgo)
+
+package hotkey
+
+import (
+	xhotkey "golang.design/x/hotkey"
+)
+
+func supported() error {
+	return nil
+}
+
+// nativeKeys 按键对应的系统键码
+var nativeKeys = map[string]xhotkey.Key{
+	"A": xhotkey.KeyA, "B": xhotkey.KeyB, "C": xhotkey.KeyC, "D": xhotkey.KeyD, "E": xhotkey.KeyE,
+	"F": xhotkey.KeyF, "G": xhotkey.KeyG, "H": xhotkey.KeyH, "I": xhotkey.KeyI, "J": xhotkey.KeyJ,
+	"K": xhotkey.KeyK, "L": xhotkey.KeyL, "M": xhotkey.KeyM, "N": xhotkey.KeyN, "O": xhotkey.KeyO,
+	"P": xhotkey.KeyP, "Q": xhotkey.KeyQ, "R": xhotkey.KeyR, "S": xhotkey.KeyS, "T": xhotkey.KeyT,
+	"U": xhotkey.KeyU, "V": xhotkey.KeyV, "W": xhotkey.KeyW, "X": xhotkey.KeyX, "Y": xhotkey.KeyY,
+	"Z": xhotkey.KeyZ,
+	"0": xhotkey.Key0, "1": xhotkey.Key1, "2": xhotkey.Key2, "3": xhotkey.Key3, "4": xhotkey.Key4,
+	"5": xhotkey.Key5, "6": xhotkey.Key6, "7": xhotkey.Key7, "8": xhotkey.Key8, "9": xhotkey.Key9,
+	"F1": xhotkey.KeyF1, "F2": xhotkey.KeyF2, "F3": xhotkey.KeyF3, "F4": xhotkey.KeyF4,
+	"F5": xhotkey.KeyF5, "F6": xhotkey.KeyF6, "F7": xhotkey.KeyF7, "F8": xhotkey.KeyF8,
+	"F9": xhotkey.KeyF9, "F10": xhotkey.KeyF10, "F11": xhotkey.KeyF11, "F12": xhotkey.KeyF12,
+	"Space": xhotkey.KeySpace, "Enter": xhotkey.KeyReturn, "Esc": xhotkey.KeyEscape,
+	"Tab": xhotkey.KeyTab, "Delete": xhotkey.KeyDelete,
+	"Left": xhotkey.KeyLeft, "Right": xhotkey.KeyRight, "Up": xhotkey.KeyUp, "Down": xhotkey.KeyDown,
+}
+
+type platformHotkey struct {
+	hk   *xhotkey.Hotkey
+	done chan struct{}
+}
+
+func (h *Hotkey) register() error {
+	mods := make([]xhotkey.Modifier, 0, len(h.Shortcut.Mods))
+	for _, m := range h.Shortcut.Mods {
+		mods = append(mods, nativeModifiers[m])
+	}
+	hk := xhotkey.New(mods, nativeKeys[h.Shortcut.Key])
+	if err := hk.Register(); err != nil {
+		return err
+	}
+
+	h.hk = hk
+	h.done = make(chan struct{})
+	go h.loop(hk.Keydown())
+	return nil
+}
+
+func (h *Hotkey) unregister() {
+	close(h.done)
+	h.hk.Unregister()
+}
+
+// loop 读取按键事件直到注销
+func (h *Hotkey) loop(keydown <-chan xhotkey.Event) {
+	for {
+		select {
+		case <-h.done:
+			return
+		case _, ok := <-keydown:
+			if !ok {
+				return
+			}
+			h.fire()
+		}
+	}
+}
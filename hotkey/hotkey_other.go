@@ -0,0 +1,15 @@
+//go:build !linux && !windows && !(darwin && cgo)
+
+package hotkey
+
+type platformHotkey struct{}
+
+func supported() error {
+	return ErrUnsupported
+}
+
+func (h *Hotkey) register() error {
+	return ErrUnsupported
+}
+
+func (h *Hotkey) unregister() {}
@@ -0,0 +1,13 @@
+package hotkey
+
+import (
+	xhotkey "golang.design/x/hotkey"
+)
+
+// nativeModifiers 修饰键对应的系统掩码
+var nativeModifiers = map[string]xhotkey.Modifier{
+	"Ctrl":  xhotkey.ModCtrl,
+	"Shift": xhotkey.ModShift,
+	"Alt":   xhotkey.ModAlt,
+	"Super": xhotkey.ModWin,
+}
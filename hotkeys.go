@@ -0,0 +1,73 @@
+package main
+
+import (
+	"log/slog"
+
+	"ccsync-net/hotkey"
+	"ccsync-net/logging"
+)
+
+// updateHotkeys 按配置重新注册全局热键，配置未变化时不做处理。
+// macOS 上注册需要等待主线程，不能在主线程调用。
+func (a *App) updateHotkeys() {
+	cfg := a.svc.Config()
+
+	a.hotkeysLock.Lock()
+	defer a.hotkeysLock.Unlock()
+	if a.hotkeysConfig != nil && *a.hotkeysConfig == cfg.Hotkeys {
+		return
+	}
+	for _, hk := range a.hotkeys {
+		hk.Unregister()
+	}
+	a.hotkeys = nil
+	a.hotkeysConfig = &cfg.Hotkeys
+
+	for _, b := range []struct {
+		name   string
+		value  string
+		action func()
+	}{
+		{"push", cfg.Hotkeys.Push, func() { a.hotkeyAction(a.svc.PushClipboard()) }},
+		{"pull", cfg.Hotkeys.Pull, func() { a.hotkeyAction(a.svc.ApplyLatest()) }},
+		{"pause", cfg.Hotkeys.Pause, a.svc.TogglePause},
+	} {
+		if b.value == "" {
+			continue
+		}
+		sc, err := hotkey.Parse(b.value)
+		if err != nil {
+			a.hotkeyLogger().Warn("hotkey.invalid", "action", b.name, "err", err)
+			continue
+		}
+		hk, err := hotkey.Register(sc, b.action)
+		if err != nil {
+			a.hotkeyLogger().Warn("hotkey.failed", "action", b.name, "err", err)
+			continue
+		}
+		a.hotkeyLogger().Info("hotkey.registered", "action", b.name, "hotkey", sc.String())
+		a.hotkeys = append(a.hotkeys, hk)
+	}
+}
+
+// unregisterHotkeys 注销所有全局热键
+func (a *App) unregisterHotkeys() {
+	a.hotkeysLock.Lock()
+	defer a.hotkeysLock.Unlock()
+	for _, hk := range a.hotkeys {
+		hk.Unregister()
+	}
+	a.hotkeys = nil
+	a.hotkeysConfig = nil
+}
+
+// hotkeyAction 记录热键操作失败的原因
+func (a *App) hotkeyAction(err error) {
+	if err != nil {
+		a.hotkeyLogger().Warn("hotkey.actionFailed", "err", err)
+	}
+}
+
+func (a *App) hotkeyLogger() *slog.Logger {
+	return logging.Component("hotkey")
+}
@@ -0,0 +1,258 @@
+package i18n
+
+// en 英文消息
+var en = map[string]string{
+	// 托盘菜单
+	"tray.show":                   "Show Main Window",
+	"tray.pause":                  "Pause Sync",
+	"tray.pause15":                "Pause for 15 Minutes",
+	"tray.quit":                   "Quit",
+	"tray.startServer":            "Start Server",
+	"tray.stopServer":             "Stop Server",
+	"tray.startMesh":              "Start Mesh",
+	"tray.stopMesh":               "Stop Mesh",
+	"tray.connect":                "Connect",
+	"tray.disconnect":             "Disconnect",
+	"tray.syncMode":               "Sync Direction",
+	"tray.syncMode.bidirectional": "Bidirectional",
+	"tray.syncMode.send_only":     "Send Only",
+	"tray.syncMode.receive_only":  "Receive Only",
+	"tray.syncMode.disabled":      "Disabled",
+	"tray.syncMode.manual":        "Manual Only",
+	"tray.push":                   "Push Clipboard",
+	"tray.pull":                   "Apply Last Received Clip",
+	"tray.recent":                 "Recent Clips",
+	"tray.recentEmpty":            "(none)",
+	"tray.recentLocal":            "Copied on this device, click to copy again",
+	"tray.recentRemote":           "From {source}, click to copy again",
+	"tray.pending":                "Pending Clips ({count})",
+	"tray.pendingItem":            "From {sender}, click to accept",
+	"tray.pendingHidden":          "(sensitive content)",
+	"tray.rejectAll":              "Reject All",
+	"tray.status.serverRunning":   "Server running (port {port}) · {clients} clients",
+	"tray.status.serverStopped":   "Server stopped",
+	"tray.status.serverError":     "Server error: {err}",
+	"tray.status.meshRunning":     "Mesh running · {peers} peers",
+	"tray.status.meshStopped":     "Mesh stopped",
+	"tray.status.connected":       "Connected to {endpoint}",
+	"tray.status.reconnecting":    "Connecting to {endpoint}...",
+	"tray.status.disconnected":    "Not connected",
+	"tray.actionFailed":           "Tray action failed",
+
+	// 状态
+	"status.connecting":        "Connecting to {addr}...",
+	"status.disconnected":      "Disconnected",
+	"status.selectingServer":   "Selecting a server automatically...",
+	"status.serverStarting":    "Starting server...",
+	"status.serverStartFailed": "Failed to start server: {err}",
+	"status.serverRunning":     "Server running (port: {port})",
+	"status.serverStopped":     "Server stopped",
+	"status.serverFailed":      "Server stopped unexpectedly: {err}",
+	"status.pushed":            "Clipboard pushed",
+	"status.pulled":            "Latest clip written to the clipboard",
+
+	// 启动
+	"main.logFileFailed":       "Unable to write log file: {err}",
+	"main.clipboardInitFailed": "Failed to initialize clipboard: {err}",
+	"main.clipboardToolHint":   "On Linux, make sure a clipboard tool is installed:",
+	"app.loadConfigFailed":     "Failed to load config",
+	"app.migrateSaveFailed":    "Failed to save migrated config",
+	"logging.rotateFailed":     "Failed to rotate log file: {err}",
+
+	// 配置
+	"config.watchFailed":         "Unable to watch config file",
+	"config.modified":            "Config file modified",
+	"config.migrating":           "Migrating config file",
+	"config.parseFailedDefault":  "Failed to parse config file, using defaults",
+	"config.parseFailedBackup":   "Failed to parse config file, backed up to {backup}: {err}",
+	"config.parseFailedNoBackup": "Failed to parse config file: {err}; backup failed: {backupErr}",
+	"config.versionTooNew":       "Config file version {version} is newer than the supported version {supported}",
+	"config.saveVersionTooNew":   "Config file was written by a newer version ({version}, supported up to {supported}); changes are not saved to avoid losing settings",
+	"config.unknownMode":         "unknown mode \"{value}\"",
+	"config.unknownSyncMode":     "unknown sync mode \"{value}\"",
+	"config.unknownLanguage":     "unsupported language \"{value}\"",
+	"config.duplicateHotkey":     "hotkey \"{value}\" is already used by another action",
+	"config.portRange":           "port {port} is out of range {min}-65535",
+	"config.invalidAddress":      "invalid address \"{value}\", expected host:port",
+	"config.invalidCIDR":         "invalid network \"{value}\"",
+	"config.empty":               "must not be empty",
+	"config.negative":            "must not be negative",
+	"config.emptyName":           "name must not be empty",
+	"config.duplicateName":       "duplicate name \"{value}\"",
+	"config.profileNotFound":     "profile \"{value}\" does not exist",
+	"config.invalidPattern":      "invalid regular expression \"{value}\": {err}",
+
+	// 服务
+	"service.reloadFailed":      "Failed to reload config file",
+	"service.limitsFailed":      "Failed to apply access control settings, keeping the previous ones",
+	"service.configFileChanged": "Config file changed on disk",
+	"service.configUpdated":     "Config updated",
+	"service.bindChanged":       "Listen addresses changed, restarting server",
+	"service.meshChanged":       "Mesh settings changed, restarting",
+	"service.profileChanged":    "Server profile changed, reconnecting",
+	"service.addressChanged":    "Server address changed, reconnecting",
+	"service.localCopy":         "Local copy",
+	"service.received":          "Clip received",
+	"service.autoClear":         "Clip will be cleared automatically",
+	"service.clipNotFound":      "Clip is no longer in the recent list",
+	"service.clipboardEmpty":    "Clipboard is empty",
+	"service.pushConcealed":     "Clipboard content is marked as sensitive by a password manager and syncing such content is off",
+	"service.noConnection":      "No connection to send to",
+	"service.nothingToPull":     "No clip has been received from other devices yet",
+	"service.pushed":            "Clipboard pushed manually",
+	"service.pulled":            "Latest clip written manually",
+	"service.pending":           "Clip received, waiting for confirmation",
+	"service.accepted":          "Clip accepted and written to the local clipboard",
+	"service.rejected":          "Clip rejected",
+	"service.pendingNotFound":   "Clip was already handled or has expired",
+	"service.senderUnverified":  "Sender {sender} is not an authenticated paired device and cannot be auto-accepted",
+	"service.confirmFailed":     "Failed to handle pending clip",
+
+	// 暂停与同步模式
+	"pause.expired":             "Pause expired, sync resumed",
+	"pause.paused":              "Sync paused",
+	"pause.sendPaused":          "Sending paused",
+	"pause.receivePaused":       "Receiving paused",
+	"pause.resumed":             "Sync resumed",
+	"pause.skipSendReceiveOnly": "Sync mode is receive only, not sending",
+	"pause.skipSendDisabled":    "Sync is disabled, not sending",
+	"pause.skipSendPaused":      "Sending is paused, not sending",
+	"pause.skipReceiveSendOnly": "Sync mode is send only, not writing to the local clipboard",
+	"pause.skipReceiveDisabled": "Sync is disabled, not writing to the local clipboard",
+	"pause.skipReceivePaused":   "Receiving is paused, not writing to the local clipboard",
+	"pause.skipSendManual":      "Sync mode is manual only, not sending",
+	"pause.skipReceiveManual":   "Sync mode is manual only, not writing to the local clipboard",
+
+	// 配对
+	"pairing.enabled":          "Pairing mode enabled, waiting for new devices",
+	"pairing.disabled":         "Pairing mode disabled",
+	"pairing.requesting":       "Requesting pairing...",
+	"pairing.failed":           "Pairing failed",
+	"pairing.succeeded":        "Paired successfully, profile saved",
+	"pairing.imported":         "Profile imported",
+	"pairing.saveDeviceFailed": "Failed to save paired device",
+	"pairing.noAddress":        "No network address other devices can connect to",
+	"pairing.request":          "Pairing request received",
+	"pairing.requestExpired":   "Pairing request timed out",
+	"pairing.requestNotFound":  "Pairing request does not exist or has expired",
+	"pairing.devicePaired":     "Device paired",
+	"pairing.rejected":         "Pairing request rejected",
+	"pairing.revoked":          "Device revoked, disconnecting",
+	"pairing.notEnabled":       "Pairing mode is not enabled on the server",
+	"pairing.invalidResponse":  "Invalid response from server",
+	"pairing.closed":           "Server closed the pairing connection",
+
+	// 服务端
+	"server.started":            "Server started",
+	"server.stopped":            "Server stopped",
+	"server.error":              "Server error",
+	"server.failed":             "Server stopped unexpectedly",
+	"server.listenFailed":       "Failed to listen",
+	"server.marshalFailed":      "Failed to encode message",
+	"server.connRejected":       "Connection rejected",
+	"server.upgradeFailed":      "Failed to upgrade connection",
+	"server.clientConnected":    "Client connected",
+	"server.clientDisconnected": "Client disconnected",
+	"server.rateLimited":        "Client is sending too fast, message dropped",
+	"sync.sendFailed":           "Failed to send message",
+	"reject.denied":             "Address not allowed",
+	"reject.origin":             "Origin not allowed",
+	"reject.tooManyClients":     "Too many clients",
+	"reject.connRate":           "Too many connection attempts",
+	"reject.unauthorized":       "Authentication failed",
+	"bind.unknown":              "unknown address or interface \"{value}\"",
+	"bind.noAddress":            "interface \"{value}\" has no usable address",
+	"limit.invalidIP":           "invalid IP address \"{value}\"",
+	"limit.invalidCIDR":         "invalid network \"{value}\"",
+
+	// REST API
+	"api.disabled":         "API is not enabled",
+	"api.unauthorized":     "Rejected API request: authentication failed",
+	"api.methodNotAllowed": "Only {method} is supported",
+	"api.tooLarge":         "Content too large",
+	"api.badRequest":       "Malformed request: {err}",
+	"api.emptyContent":     "Content must not be empty",
+	"api.noContent":        "No content yet",
+	"api.clipPosted":       "Clip posted via API",
+
+	// 客户端
+	"client.connecting":      "Connecting",
+	"client.connected":       "Connected",
+	"client.connectFailed":   "Connection failed",
+	"client.meshYield":       "Peer has the smaller device ID, waiting for it to connect",
+	"client.disconnected":    "Disconnected",
+	"client.reconnecting":    "Connection lost, reconnecting in 3 seconds...",
+	"client.readFailed":      "Failed to read message",
+	"client.noServer":        "No server to connect to",
+	"endpoint.noCertificate": "Server did not present a certificate",
+	"endpoint.pinMismatch":   "Server certificate fingerprint does not match",
+	"uri.invalidScheme":      "Not a valid {scheme}:// connection URI",
+	"uri.missingHost":        "Connection URI is missing host or port",
+
+	// 局域网
+	"mesh.discoveryStarted":     "LAN discovery started",
+	"mesh.discoveryFailed":      "Failed to start LAN discovery",
+	"mesh.peerFound":            "Peer discovered",
+	"mesh.peerAddrChanged":      "Peer address changed",
+	"mesh.peerOffline":          "Peer went offline",
+	"mesh.proofMismatch":        "Peer failed to prove it holds the same secret",
+	"discovery.broadcastFailed": "Failed to send discovery broadcast",
+
+	// 剪贴板
+	"clipboard.monitorStarted":     "Clipboard monitor started",
+	"clipboard.monitorStopped":     "Clipboard monitor stopped",
+	"clipboard.changed":            "Clipboard content changed",
+	"clipboard.concealed":          "Concealed content detected, skipping sync",
+	"clipboard.expiredCleared":     "Expired clip cleared",
+	"clipboard.expiredRestored":    "Expired clip replaced with previous content",
+	"clipboard.clearFailed":        "Failed to clear clipboard (wl-copy --clear)",
+	"clipboard.writeFailed":        "Failed to write clipboard (wl-copy)",
+	"clipboard.pipeFailed":         "Failed to create stdin pipe for wl-copy",
+	"clipboard.watcherStarting":    "Starting wl-paste --watch monitor...",
+	"clipboard.watcherStartFailed": "Failed to start wl-paste watcher",
+	"clipboard.watcherFailed":      "wl-paste watcher failed",
+
+	// 全局热键
+	"hotkey.unsupported":     "Global hotkeys are not supported on this system (Linux needs an X11 session, not Wayland)",
+	"hotkey.noModifier":      "hotkey \"{value}\" needs at least one modifier (Ctrl, Shift, Alt, Super)",
+	"hotkey.unknownModifier": "unknown modifier \"{value}\"",
+	"hotkey.unknownKey":      "unsupported key \"{value}\"",
+	"hotkey.registerFailed":  "failed to register hotkey {hotkey}: {err}",
+	"hotkey.invalid":         "Invalid hotkey setting",
+	"hotkey.failed":          "Hotkey unavailable",
+	"hotkey.registered":      "Global hotkey registered",
+	"hotkey.actionFailed":    "Hotkey action failed",
+
+	// 桌面通知
+	"notify.unsupported":       "Desktop notifications are not supported on this system",
+	"notify.failed":            "Failed to show desktop notification",
+	"notify.clipTitle":         "Clip received from {sender}",
+	"notify.clipHidden":        "(sensitive content hidden)",
+	"notify.pendingTitle":      "Clip from {sender} waiting for confirmation",
+	"notify.accept":            "Accept",
+	"notify.reject":            "Reject",
+	"notify.lostTitle":         "Connection lost",
+	"notify.lost":              "Lost connection to {endpoint}, reconnecting",
+	"notify.restoredTitle":     "Connection restored",
+	"notify.restored":          "Reconnected to {endpoint}",
+	"notify.serverFailedTitle": "Server stopped",
+	"notify.peerTitle":         "New device connected",
+	"notify.peer":              "{name} ({addr})",
+	"notify.unknownDevice":     "Unpaired device",
+
+	// 中继
+	"relay.noSecret":       "No secret set, anyone can connect",
+	"relay.invalidLimits":  "Invalid access control settings",
+	"relay.startFailed":    "Failed to start",
+	"relay.flagPort":       "Port to listen on",
+	"relay.flagBind":       "Comma-separated addresses or interface names to listen on",
+	"relay.flagSecret":     "Client authentication secret",
+	"relay.flagKeepLast":   "Keep the latest clip of each channel",
+	"relay.flagAPIToken":   "REST API access token",
+	"relay.flagAllow":      "Comma-separated networks allowed to connect",
+	"relay.flagDeny":       "Comma-separated networks denied from connecting",
+	"relay.flagMaxClients": "Maximum concurrent connections",
+	"relay.flagConnRate":   "Maximum connections per IP per minute",
+	"relay.flagMsgRate":    "Maximum messages per client per second",
+	"relay.flagDebug":      "Enable debug logging",
+}
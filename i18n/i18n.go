@@ -0,0 +1,190 @@
+// Package i18n 提供界面与日志消息的多语言支持。
+//
+// 消息以 key 标识，模板中的 {name} 由成对传入的参数替换。
+// 发送给前端的事件使用 Message，携带 key 和参数，前端可按自己的语言重新翻译。
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// 支持的语言
+const (
+	ZhCN = "zh-CN"
+	En   = "en"
+)
+
+// DefaultLocale 无法识别系统语言时使用的语言
+const DefaultLocale = ZhCN
+
+var catalogs = map[string]map[string]string{
+	ZhCN: zhCN,
+	En:   en,
+}
+
+var current atomic.Value
+
+func init() {
+	current.Store(Detect())
+}
+
+// Locales 返回支持的语言列表
+func Locales() []string {
+	return []string{ZhCN, En}
+}
+
+// SetLocale 设置当前语言，tag 为空或不支持时跟随系统语言
+func SetLocale(tag string) {
+	if l := Normalize(tag); l != "" {
+		current.Store(l)
+		return
+	}
+	current.Store(Detect())
+}
+
+// Locale 返回当前语言
+func Locale() string {
+	return current.Load().(string)
+}
+
+// Normalize 将语言标签 (如 "zh_CN.UTF-8"、"en-US") 转换为支持的语言，不支持时返回空字符串
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	switch {
+	case tag == "":
+		return ""
+	case strings.HasPrefix(tag, "zh"):
+		return ZhCN
+	case strings.HasPrefix(tag, "en"):
+		return En
+	}
+	return ""
+}
+
+// Detect 根据环境变量和系统设置检测语言
+func Detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			if l := Normalize(v); l != "" {
+				return l
+			}
+			// C、POSIX 等未指定语言的设置继续尝试系统设置
+			break
+		}
+	}
+	if l := Normalize(systemLocale()); l != "" {
+		return l
+	}
+	return DefaultLocale
+}
+
+// T 按当前语言翻译消息，args 为成对的参数名和值
+func T(key string, args ...any) string {
+	return Translate(Locale(), key, params(args))
+}
+
+// Translate 按指定语言翻译消息，缺少翻译时依次使用默认语言和 key 本身
+func Translate(locale, key string, params map[string]any) string {
+	tmpl, ok := catalogs[locale][key]
+	if !ok {
+		if tmpl, ok = catalogs[DefaultLocale][key]; !ok {
+			tmpl = key
+		}
+	}
+	if len(params) == 0 {
+		return tmpl
+	}
+
+	pairs := make([]string, 0, len(params)*2)
+	for name, v := range params {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(v))
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
+
+// Messages 返回指定语言的全部消息模板，供前端翻译事件
+func Messages(locale string) map[string]string {
+	if l := Normalize(locale); l != "" {
+		locale = l
+	} else {
+		locale = Locale()
+	}
+
+	messages := make(map[string]string, len(catalogs[DefaultLocale]))
+	for k, v := range catalogs[DefaultLocale] {
+		messages[k] = v
+	}
+	for k, v := range catalogs[locale] {
+		messages[k] = v
+	}
+	return messages
+}
+
+// Message 带参数的消息
+type Message struct {
+	Key    string         `json:"key"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// M 创建消息，args 为成对的参数名和值
+func M(key string, args ...any) Message {
+	return Message{Key: key, Params: params(args)}
+}
+
+// String 返回按当前语言翻译后的文本
+func (m Message) String() string {
+	return Translate(Locale(), m.Key, m.Params)
+}
+
+// MarshalJSON 序列化时附带当前语言的文本，前端没有对应翻译时可直接显示
+func (m Message) MarshalJSON() ([]byte, error) {
+	type message Message
+	return json.Marshal(struct {
+		message
+		Text string `json:"text"`
+	}{message(m), m.String()})
+}
+
+// Error 可翻译的错误
+type Error struct {
+	Message
+}
+
+// Errorf 创建可翻译的错误，args 为成对的参数名和值
+func Errorf(key string, args ...any) error {
+	return &Error{M(key, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.String()
+}
+
+// params 将成对的参数转换为 map，error 参数转换为其文本
+func params(args []any) map[string]any {
+	if len(args) < 2 {
+		return nil
+	}
+	p := make(map[string]any, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		name, ok := args[i].(string)
+		if !ok {
+			continue
+		}
+		v := args[i+1]
+		switch x := v.(type) {
+		case error:
+			v = x.Error()
+		case fmt.Stringer:
+			v = x.String()
+		}
+		p[name] = v
+	}
+	return p
+}
@@ -0,0 +1,188 @@
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+)
+
+// withMessages 临时加入测试用的消息模板
+func withMessages(t *testing.T, locale string, messages map[string]string) {
+	t.Helper()
+	for k, v := range messages {
+		catalogs[locale][k] = v
+	}
+	t.Cleanup(func() {
+		for k := range messages {
+			delete(catalogs[locale], k)
+		}
+	})
+}
+
+func TestTranslate(t *testing.T) {
+	withMessages(t, ZhCN, map[string]string{
+		"test.both":    "你好 {name}",
+		"test.zhOnly":  "仅中文 {n}",
+		"test.repeat":  "{a}-{a}-{b}",
+		"test.literal": "{name} 和 {other}",
+	})
+	withMessages(t, En, map[string]string{
+		"test.both": "Hello {name}",
+	})
+
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		params map[string]any
+		want   string
+	}{
+		{name: "translated", locale: En, key: "test.both", params: map[string]any{"name": "Ann"}, want: "Hello Ann"},
+		{name: "default locale", locale: ZhCN, key: "test.both", params: map[string]any{"name": "Ann"}, want: "你好 Ann"},
+		{name: "missing translation", locale: En, key: "test.zhOnly", params: map[string]any{"n": 3}, want: "仅中文 3"},
+		{name: "unknown locale", locale: "fr", key: "test.both", params: map[string]any{"name": "Ann"}, want: "你好 Ann"},
+		{name: "unknown key", locale: En, key: "test.unknown", want: "test.unknown"},
+		{name: "no params", locale: En, key: "test.both", want: "Hello {name}"},
+		{name: "repeated", locale: ZhCN, key: "test.repeat", params: map[string]any{"a": 1, "b": true}, want: "1-1-true"},
+		{name: "missing param", locale: ZhCN, key: "test.literal", params: map[string]any{"name": "x"}, want: "x 和 {other}"},
+		{name: "no recursion", locale: ZhCN, key: "test.literal", params: map[string]any{"name": "{other}", "other": "y"}, want: "{other} 和 y"},
+	}
+	for _, tt := range tests {
+		if got := Translate(tt.locale, tt.key, tt.params); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParams(t *testing.T) {
+	withMessages(t, En, map[string]string{"test.param": "[{v}]"})
+	SetLocale(En)
+	t.Cleanup(func() { SetLocale("") })
+
+	tests := []struct {
+		name string
+		args []any
+		want string
+	}{
+		{name: "string", args: []any{"v", "text"}, want: "[text]"},
+		{name: "number", args: []any{"v", 42}, want: "[42]"},
+		{name: "error", args: []any{"v", errors.New("boom")}, want: "[boom]"},
+		{name: "translatable error", args: []any{"v", Errorf("test.param", "v", "inner")}, want: "[[inner]]"},
+		{name: "message", args: []any{"v", M("test.param", "v", 1)}, want: "[[1]]"},
+		{name: "odd count", args: []any{"v", 1, "w"}, want: "[1]"},
+		{name: "non-string name", args: []any{1, 2, "v", 3}, want: "[3]"},
+		{name: "none", want: "[{v}]"},
+	}
+	for _, tt := range tests {
+		if got := T("test.param", tt.args...); got != tt.want {
+			t.Errorf("%s: T = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := Errorf("test.param", tt.args...).Error(); got != tt.want {
+			t.Errorf("%s: Errorf = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMessageJSON(t *testing.T) {
+	withMessages(t, En, map[string]string{"test.json": "{count} clips"})
+	SetLocale(En)
+	t.Cleanup(func() { SetLocale("") })
+
+	data, err := json.Marshal(M("test.json", "count", 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"key":"test.json","params":{"count":2},"text":"2 clips"}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"zh_CN.UTF-8": ZhCN,
+		"zh-TW":       ZhCN,
+		"ZH":          ZhCN,
+		"en_US.UTF-8": En,
+		"en-GB@euro":  En,
+		" en ":        En,
+		"C":           "",
+		"POSIX":       "",
+		"fr_FR":       "",
+		"":            "",
+	}
+	for tag, want := range tests {
+		if got := Normalize(tag); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestSetLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "en_US.UTF-8")
+	t.Cleanup(func() { SetLocale("") })
+
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "zh-CN", want: ZhCN},
+		{tag: "en", want: En},
+		{tag: "zh_CN.UTF-8", want: ZhCN},
+		{tag: "", want: En},   // 跟随系统语言
+		{tag: "fr", want: En}, // 不支持时跟随系统语言
+	}
+	for _, tt := range tests {
+		SetLocale(tt.tag)
+		if got := Locale(); got != tt.want {
+			t.Errorf("SetLocale(%q): Locale = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestMessagesFallback(t *testing.T) {
+	withMessages(t, ZhCN, map[string]string{"test.zhOnly": "仅中文"})
+
+	if got := Messages(En)["test.zhOnly"]; got != "仅中文" {
+		t.Errorf("Messages(en) fallback = %q", got)
+	}
+	if got, want := Messages("en-US")["tray.quit"], en["tray.quit"]; got != want {
+		t.Errorf("Messages(en-US) = %q, want %q", got, want)
+	}
+}
+
+// TestCatalogsComplete 检查两种语言的 key 与参数一致
+func TestCatalogsComplete(t *testing.T) {
+	placeholder := regexp.MustCompile(`\{\w+\}`)
+	names := func(s string) map[string]bool {
+		m := make(map[string]bool)
+		for _, p := range placeholder.FindAllString(s, -1) {
+			m[p] = true
+		}
+		return m
+	}
+
+	for key, zh := range zhCN {
+		text, ok := en[key]
+		if !ok {
+			t.Errorf("%s: missing in en", key)
+			continue
+		}
+		zhNames, enNames := names(zh), names(text)
+		for p := range zhNames {
+			if !enNames[p] {
+				t.Errorf("%s: en lacks %s", key, p)
+			}
+		}
+		for p := range enNames {
+			if !zhNames[p] {
+				t.Errorf("%s: zh-CN lacks %s", key, p)
+			}
+		}
+	}
+	for key := range en {
+		if _, ok := zhCN[key]; !ok {
+			t.Errorf("%s: missing in zh-CN", key)
+		}
+	}
+}
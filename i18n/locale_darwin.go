@@ -0,0 +1,16 @@
+package i18n
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// systemLocale 返回 macOS 的首选区域设置，如 "zh_CN"。
+// 从 Finder 启动的程序没有 LANG 环境变量，需要读取系统设置。
+func systemLocale() string {
+	out, err := exec.Command("defaults", "read", "-g", "AppleLocale").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,14 @@
+//go:build !windows && !darwin
+
+package i18n
+
+import (
+	"os"
+	"strings"
+)
+
+// systemLocale 返回 LANGUAGE 中的首选语言
+func systemLocale() string {
+	lang, _, _ := strings.Cut(os.Getenv("LANGUAGE"), ":")
+	return lang
+}
@@ -0,0 +1,19 @@
+package i18n
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetUserDefaultLocaleName = syscall.NewLazyDLL("kernel32.dll").NewProc("GetUserDefaultLocaleName")
+
+// systemLocale 返回 Windows 用户的区域设置，如 "zh-CN"
+func systemLocale() string {
+	const localeNameMaxLength = 85
+	buf := make([]uint16, localeNameMaxLength)
+	n, _, _ := procGetUserDefaultLocaleName.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if n == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf)
+}
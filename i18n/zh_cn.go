@@ -0,0 +1,258 @@
+package i18n
+
+// zhCN 简体中文消息
+var zhCN = map[string]string{
+	// 托盘菜单
+	"tray.show":                   "显示主窗口",
+	"tray.pause":                  "暂停同步",
+	"tray.pause15":                "暂停 15 分钟",
+	"tray.quit":                   "退出",
+	"tray.startServer":            "启动服务端",
+	"tray.stopServer":             "停止服务端",
+	"tray.startMesh":              "启动 mesh",
+	"tray.stopMesh":               "停止 mesh",
+	"tray.connect":                "连接服务端",
+	"tray.disconnect":             "断开连接",
+	"tray.syncMode":               "同步方向",
+	"tray.syncMode.bidirectional": "双向同步",
+	"tray.syncMode.send_only":     "只发送",
+	"tray.syncMode.receive_only":  "只接收",
+	"tray.syncMode.disabled":      "不同步",
+	"tray.syncMode.manual":        "仅手动",
+	"tray.push":                   "发送当前剪贴板",
+	"tray.pull":                   "写入最近收到的内容",
+	"tray.recent":                 "最近内容",
+	"tray.recentEmpty":            "(暂无)",
+	"tray.recentLocal":            "本机复制，点击重新复制",
+	"tray.recentRemote":           "来自 {source}，点击重新复制",
+	"tray.pending":                "待确认内容 ({count})",
+	"tray.pendingItem":            "来自 {sender}，点击接受",
+	"tray.pendingHidden":          "（敏感内容）",
+	"tray.rejectAll":              "全部拒绝",
+	"tray.status.serverRunning":   "服务端运行中 (端口 {port}) · {clients} 个客户端",
+	"tray.status.serverStopped":   "服务端未运行",
+	"tray.status.serverError":     "服务端异常: {err}",
+	"tray.status.meshRunning":     "mesh 运行中 · {peers} 个节点",
+	"tray.status.meshStopped":     "mesh 未运行",
+	"tray.status.connected":       "已连接到 {endpoint}",
+	"tray.status.reconnecting":    "正在连接 {endpoint}...",
+	"tray.status.disconnected":    "未连接",
+	"tray.actionFailed":           "托盘操作失败",
+
+	// 状态
+	"status.connecting":        "正在连接到 {addr}...",
+	"status.disconnected":      "已断开连接",
+	"status.selectingServer":   "正在自动选择服务端...",
+	"status.serverStarting":    "正在启动服务端...",
+	"status.serverStartFailed": "服务端启动失败: {err}",
+	"status.serverRunning":     "服务端运行中 (端口: {port})",
+	"status.serverStopped":     "服务端已停止",
+	"status.serverFailed":      "服务端异常停止: {err}",
+	"status.pushed":            "已发送当前剪贴板内容",
+	"status.pulled":            "已写入最新收到的内容",
+
+	// 启动
+	"main.logFileFailed":       "无法写入日志文件: {err}",
+	"main.clipboardInitFailed": "剪贴板初始化失败: {err}",
+	"main.clipboardToolHint":   "在 Linux 系统上，请确保已安装剪贴板工具：",
+	"app.loadConfigFailed":     "加载配置失败",
+	"app.migrateSaveFailed":    "保存升级后的配置失败",
+	"logging.rotateFailed":     "日志文件轮转失败: {err}",
+
+	// 配置
+	"config.watchFailed":         "无法监视配置文件",
+	"config.modified":            "配置文件已修改",
+	"config.migrating":           "迁移配置文件",
+	"config.parseFailedDefault":  "配置文件解析失败，已使用默认配置",
+	"config.parseFailedBackup":   "配置文件解析失败，已备份到 {backup}: {err}",
+	"config.parseFailedNoBackup": "配置文件解析失败: {err}，备份失败: {backupErr}",
+	"config.versionTooNew":       "配置文件版本 {version} 高于当前支持的版本 {supported}",
+	"config.saveVersionTooNew":   "配置文件由更新版本 ({version}) 的程序创建，当前只支持到版本 {supported}，为避免丢失设置不会保存修改",
+	"config.unknownMode":         "未知的运行模式 \"{value}\"",
+	"config.unknownSyncMode":     "未知的同步模式 \"{value}\"",
+	"config.unknownLanguage":     "不支持的语言 \"{value}\"",
+	"config.duplicateHotkey":     "热键 \"{value}\" 已被其他操作使用",
+	"config.portRange":           "端口 {port} 超出范围 {min}-65535",
+	"config.invalidAddress":      "地址 \"{value}\" 格式不正确，应为 host:port",
+	"config.invalidCIDR":         "无效的网段 \"{value}\"",
+	"config.empty":               "不能为空",
+	"config.negative":            "不能为负数",
+	"config.emptyName":           "名称不能为空",
+	"config.duplicateName":       "名称 \"{value}\" 重复",
+	"config.profileNotFound":     "配置方案 \"{value}\" 不存在",
+	"config.invalidPattern":      "正则表达式 \"{value}\" 无效: {err}",
+
+	// 服务
+	"service.reloadFailed":      "配置文件重新加载失败",
+	"service.limitsFailed":      "应用访问控制设置失败，继续使用之前的设置",
+	"service.configFileChanged": "检测到配置文件被修改",
+	"service.configUpdated":     "配置已更新",
+	"service.bindChanged":       "监听地址已变更，正在重启服务端",
+	"service.meshChanged":       "mesh 设置已变更，正在重启",
+	"service.profileChanged":    "服务端配置方案已变更，正在重新连接",
+	"service.addressChanged":    "服务端地址已变更，正在重新连接",
+	"service.localCopy":         "本地复制",
+	"service.received":          "收到同步",
+	"service.autoClear":         "该内容将自动清除",
+	"service.clipNotFound":      "该内容已不在最近列表中",
+	"service.clipboardEmpty":    "剪贴板为空",
+	"service.pushConcealed":     "剪贴板内容被密码管理器标记为敏感，未开启同步敏感内容",
+	"service.noConnection":      "没有可发送的连接",
+	"service.nothingToPull":     "还没有收到过其他设备的内容",
+	"service.pushed":            "已手动发送剪贴板内容",
+	"service.pulled":            "已手动写入最新收到的内容",
+	"service.pending":           "收到内容，等待确认",
+	"service.accepted":          "已接受并写入本地剪贴板",
+	"service.rejected":          "已拒绝收到的内容",
+	"service.pendingNotFound":   "该内容已处理或已过期",
+	"service.senderUnverified":  "发送方 {sender} 不是经过认证的已配对设备，不能设为自动接受",
+	"service.confirmFailed":     "处理待确认内容失败",
+
+	// 暂停与同步模式
+	"pause.expired":             "暂停时间已到，恢复同步",
+	"pause.paused":              "已暂停同步",
+	"pause.sendPaused":          "已暂停发送",
+	"pause.receivePaused":       "已暂停接收",
+	"pause.resumed":             "已恢复同步",
+	"pause.skipSendReceiveOnly": "同步模式为只入，跳过发送",
+	"pause.skipSendDisabled":    "同步已禁用，跳过发送",
+	"pause.skipSendPaused":      "发送已暂停，跳过发送",
+	"pause.skipReceiveSendOnly": "同步模式为只出，跳过写入本地剪贴板",
+	"pause.skipReceiveDisabled": "同步已禁用，跳过写入本地剪贴板",
+	"pause.skipReceivePaused":   "接收已暂停，跳过写入本地剪贴板",
+	"pause.skipSendManual":      "同步模式为仅手动，跳过发送",
+	"pause.skipReceiveManual":   "同步模式为仅手动，未写入本地剪贴板",
+
+	// 配对
+	"pairing.enabled":          "已开启配对模式，等待新设备请求配对",
+	"pairing.disabled":         "已关闭配对模式",
+	"pairing.requesting":       "正在请求配对...",
+	"pairing.failed":           "配对失败",
+	"pairing.succeeded":        "配对成功，已保存配置方案",
+	"pairing.imported":         "已导入配置方案",
+	"pairing.saveDeviceFailed": "保存配对设备失败",
+	"pairing.noAddress":        "没有可供其他设备连接的网络地址",
+	"pairing.request":          "收到配对请求",
+	"pairing.requestExpired":   "配对请求已超时",
+	"pairing.requestNotFound":  "配对请求不存在或已过期",
+	"pairing.devicePaired":     "设备已配对",
+	"pairing.rejected":         "配对请求被拒绝",
+	"pairing.revoked":          "设备已被撤销，断开连接",
+	"pairing.notEnabled":       "服务端未开启配对模式",
+	"pairing.invalidResponse":  "服务端响应无效",
+	"pairing.closed":           "服务端关闭了配对连接",
+
+	// 服务端
+	"server.started":            "服务端已启动",
+	"server.stopped":            "服务端已停止",
+	"server.error":              "服务端错误",
+	"server.failed":             "服务端异常停止",
+	"server.listenFailed":       "监听失败",
+	"server.marshalFailed":      "消息序列化失败",
+	"server.connRejected":       "拒绝连接",
+	"server.upgradeFailed":      "连接升级失败",
+	"server.clientConnected":    "新客户端连接",
+	"server.clientDisconnected": "客户端断开",
+	"server.rateLimited":        "客户端发送过于频繁，丢弃消息",
+	"sync.sendFailed":           "发送消息失败",
+	"reject.denied":             "不在允许的地址范围内",
+	"reject.origin":             "来源网页不被允许",
+	"reject.tooManyClients":     "已达到最大连接数",
+	"reject.connRate":           "连接过于频繁",
+	"reject.unauthorized":       "认证失败",
+	"bind.unknown":              "未知的地址或网卡 \"{value}\"",
+	"bind.noAddress":            "网卡 \"{value}\" 没有可用地址",
+	"limit.invalidIP":           "无效的 IP 地址 \"{value}\"",
+	"limit.invalidCIDR":         "无效的网段 \"{value}\"",
+
+	// REST API
+	"api.disabled":         "API 未启用",
+	"api.unauthorized":     "拒绝 API 请求: 认证失败",
+	"api.methodNotAllowed": "仅支持 {method}",
+	"api.tooLarge":         "内容过大",
+	"api.badRequest":       "请求格式不正确: {err}",
+	"api.emptyContent":     "内容不能为空",
+	"api.noContent":        "暂无内容",
+	"api.clipPosted":       "通过 API 发送内容",
+
+	// 客户端
+	"client.connecting":      "正在连接",
+	"client.connected":       "连接成功",
+	"client.connectFailed":   "连接失败",
+	"client.meshYield":       "对方节点设备 ID 较小，等待对方连接",
+	"client.disconnected":    "已断开连接",
+	"client.reconnecting":    "连接断开，3秒后重连...",
+	"client.readFailed":      "读取消息失败",
+	"client.noServer":        "没有可连接的服务端",
+	"endpoint.noCertificate": "服务端未提供证书",
+	"endpoint.pinMismatch":   "服务端证书指纹不匹配",
+	"uri.invalidScheme":      "不是有效的 {scheme}:// 连接地址",
+	"uri.missingHost":        "连接地址缺少主机或端口",
+
+	// 局域网
+	"mesh.discoveryStarted":     "局域网发现已启动",
+	"mesh.discoveryFailed":      "局域网发现启动失败",
+	"mesh.peerFound":            "发现节点",
+	"mesh.peerAddrChanged":      "节点地址变更",
+	"mesh.peerOffline":          "节点已离线",
+	"mesh.proofMismatch":        "对方节点未能证明持有相同的密钥",
+	"discovery.broadcastFailed": "发送发现广播失败",
+
+	// 剪贴板
+	"clipboard.monitorStarted":     "剪贴板监听已启动",
+	"clipboard.monitorStopped":     "剪贴板监听已停止",
+	"clipboard.changed":            "剪贴板内容已变化",
+	"clipboard.concealed":          "检测到敏感标记的内容，跳过同步",
+	"clipboard.expiredCleared":     "已清除过期内容",
+	"clipboard.expiredRestored":    "已清除过期内容并恢复之前的内容",
+	"clipboard.clearFailed":        "清空剪贴板失败 (wl-copy --clear)",
+	"clipboard.writeFailed":        "写入剪贴板失败 (wl-copy)",
+	"clipboard.pipeFailed":         "无法创建 wl-copy 输入管道",
+	"clipboard.watcherStarting":    "正在启动 wl-paste --watch 监听...",
+	"clipboard.watcherStartFailed": "无法启动 wl-paste 监听",
+	"clipboard.watcherFailed":      "wl-paste 监听运行失败",
+
+	// 全局热键
+	"hotkey.unsupported":     "当前系统不支持全局热键 (Linux 上需要 X11 会话，不支持 Wayland)",
+	"hotkey.noModifier":      "热键 \"{value}\" 需要至少一个修饰键 (Ctrl、Shift、Alt、Super)",
+	"hotkey.unknownModifier": "未知的修饰键 \"{value}\"",
+	"hotkey.unknownKey":      "不支持的按键 \"{value}\"",
+	"hotkey.registerFailed":  "注册热键 {hotkey} 失败: {err}",
+	"hotkey.invalid":         "热键设置无效",
+	"hotkey.failed":          "热键不可用",
+	"hotkey.registered":      "已注册全局热键",
+	"hotkey.actionFailed":    "热键操作失败",
+
+	// 桌面通知
+	"notify.unsupported":       "当前系统不支持桌面通知",
+	"notify.failed":            "发送桌面通知失败",
+	"notify.clipTitle":         "收到来自 {sender} 的内容",
+	"notify.clipHidden":        "（敏感内容已隐藏）",
+	"notify.pendingTitle":      "来自 {sender} 的内容等待确认",
+	"notify.accept":            "接受",
+	"notify.reject":            "拒绝",
+	"notify.lostTitle":         "连接已断开",
+	"notify.lost":              "与 {endpoint} 的连接已断开，正在重连",
+	"notify.restoredTitle":     "连接已恢复",
+	"notify.restored":          "已重新连接到 {endpoint}",
+	"notify.serverFailedTitle": "服务端已停止",
+	"notify.peerTitle":         "新设备已连接",
+	"notify.peer":              "{name} ({addr})",
+	"notify.unknownDevice":     "未配对设备",
+
+	// 中继
+	"relay.noSecret":       "未设置认证密钥，任何人都可以连接",
+	"relay.invalidLimits":  "访问控制设置有误",
+	"relay.startFailed":    "启动失败",
+	"relay.flagPort":       "监听端口",
+	"relay.flagBind":       "监听的地址或网卡名称，逗号分隔",
+	"relay.flagSecret":     "客户端认证密钥",
+	"relay.flagKeepLast":   "保存各频道最近一条内容",
+	"relay.flagAPIToken":   "REST API 访问令牌",
+	"relay.flagAllow":      "允许连接的网段，逗号分隔",
+	"relay.flagDeny":       "拒绝连接的网段，逗号分隔",
+	"relay.flagMaxClients": "最大同时连接数",
+	"relay.flagConnRate":   "每个 IP 每分钟最多建立的连接数",
+	"relay.flagMsgRate":    "每个客户端每秒最多发送的消息数",
+	"relay.flagDebug":      "输出调试级别日志",
+}
@@ -6,3 +6,11 @@ import _ "embed"
 
 //go:embed build/ccsync-net.png
 var iconData []byte
+
+// iconPNG 生成状态图标所用的 PNG 原图
+var iconPNG = iconData
+
+// encodeIcon 将 PNG 图标转换为托盘所需的格式
+func encodeIcon(png []byte) []byte {
+	return png
+}
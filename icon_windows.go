@@ -1,6 +1,28 @@
 package main
 
-import _ "embed"
+import (
+	"bytes"
+	_ "embed"
+	"encoding/binary"
+)
 
 //go:embed build/windows/icon.ico
 var iconData []byte
+
+// iconPNG 生成状态图标所用的 PNG 原图
+//
+//go:embed build/ccsync-net.png
+var iconPNG []byte
+
+// encodeIcon 将 PNG 图标封装为 ICO，Windows Vista 起支持内嵌 PNG 的 ICO
+func encodeIcon(png []byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, struct{ Reserved, Type, Count uint16 }{0, 1, 1})
+	binary.Write(&buf, binary.LittleEndian, struct {
+		Width, Height, Colors, Reserved uint8
+		Planes, BitCount                uint16
+		Size, Offset                    uint32
+	}{0, 0, 0, 0, 1, 32, uint32(len(png)), 6 + 16}) // 宽高为 0 表示 256
+	buf.Write(png)
+	return buf.Bytes()
+}
@@ -0,0 +1,265 @@
+// Package logging 提供全局的结构化分级日志。
+//
+// 日志同时输出到标准错误、~/.ccsync-net/logs/ 下按大小轮转的文件，
+// 以及通过 Subscribe 订阅的界面。剪贴板内容只能通过 Content 记录，
+// 仅在调试模式且显式开启记录内容时才会写出原文。
+//
+// 日志消息使用 i18n 的消息 key，输出时按当前语言翻译，
+// 界面收到的 Entry 同时带有 key，可按界面语言重新翻译。
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"ccsync-net/i18n"
+)
+
+// Entry 供界面显示的日志记录
+type Entry struct {
+	Time      int64             `json:"time"`
+	Level     string            `json:"level"` // debug, info, warn, error
+	Component string            `json:"component,omitempty"`
+	Key       string            `json:"key,omitempty"` // 消息 key，未登记的消息为空
+	Message   string            `json:"message"`
+	Attrs     map[string]string `json:"attrs,omitempty"`
+}
+
+var (
+	level       = new(slog.LevelVar)
+	withContent atomic.Bool
+
+	subscribers     = make(map[int]func(Entry))
+	nextSubscriber  int
+	subscribersLock sync.RWMutex
+
+	file *rotatingFile
+)
+
+// Init 初始化全局日志，输出到标准错误；dir 不为空时同时写入该目录下的日志文件
+func Init(dir string) error {
+	handlers := []slog.Handler{
+		slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}),
+		&sinkHandler{},
+	}
+
+	var err error
+	if dir != "" {
+		var f *rotatingFile
+		if f, err = openRotatingFile(dir, "ccsync.log", maxFileSize, maxFiles); err == nil {
+			file = f
+			handlers = append(handlers, slog.NewJSONHandler(f, &slog.HandlerOptions{Level: level}))
+		}
+	}
+
+	slog.SetDefault(slog.New(&fanout{handlers: handlers}))
+	return err
+}
+
+// DefaultDir 返回默认的日志目录 ~/.ccsync-net/logs
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".ccsync-net", "logs"), nil
+}
+
+// Close 关闭日志文件
+func Close() error {
+	if file == nil {
+		return nil
+	}
+	return file.Close()
+}
+
+// SetDebug 开启或关闭调试级别日志
+func SetDebug(debug bool) {
+	if debug {
+		level.Set(slog.LevelDebug)
+	} else {
+		level.Set(slog.LevelInfo)
+	}
+}
+
+// SetLogContent 设置调试模式下是否记录剪贴板内容原文
+func SetLogContent(enabled bool) {
+	withContent.Store(enabled)
+}
+
+// Content 返回剪贴板内容的日志属性。
+// 仅在调试模式且开启了记录内容时包含原文，否则只记录长度。
+func Content(content string) slog.Attr {
+	if withContent.Load() && level.Level() <= slog.LevelDebug {
+		return slog.String("content", content)
+	}
+	return slog.Int("contentLength", len([]rune(content)))
+}
+
+// Component 返回带组件名称的日志记录器
+func Component(name string) *slog.Logger {
+	return slog.Default().With("component", name)
+}
+
+// Subscribe 订阅日志记录，返回取消订阅的函数。
+// 回调在记录日志的协程中同步调用，不应阻塞。
+func Subscribe(fn func(Entry)) func() {
+	subscribersLock.Lock()
+	id := nextSubscriber
+	nextSubscriber++
+	subscribers[id] = fn
+	subscribersLock.Unlock()
+
+	return func() {
+		subscribersLock.Lock()
+		delete(subscribers, id)
+		subscribersLock.Unlock()
+	}
+}
+
+// fanout 将日志分发给多个 Handler
+type fanout struct {
+	handlers []slog.Handler
+}
+
+func (f *fanout) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range f.handlers {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fanout) Handle(ctx context.Context, r slog.Record) error {
+	key := r.Message
+	r.Message = i18n.T(key)
+	if r.Message == key {
+		key = ""
+	}
+
+	var first error
+	for _, h := range f.handlers {
+		if !h.Enabled(ctx, r.Level) {
+			continue
+		}
+		var err error
+		if sink, ok := h.(*sinkHandler); ok {
+			err = sink.handle(r.Clone(), key)
+		} else {
+			err = h.Handle(ctx, r.Clone())
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (f *fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return &fanout{handlers: handlers}
+}
+
+func (f *fanout) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(f.handlers))
+	for i, h := range f.handlers {
+		handlers[i] = h.WithGroup(name)
+	}
+	return &fanout{handlers: handlers}
+}
+
+// sinkHandler 将日志转换为 Entry 发送给订阅者
+type sinkHandler struct {
+	attrs  []slog.Attr
+	prefix string // 分组前缀
+}
+
+func (h *sinkHandler) Enabled(_ context.Context, l slog.Level) bool {
+	if l < level.Level() {
+		return false
+	}
+	subscribersLock.RLock()
+	defer subscribersLock.RUnlock()
+	return len(subscribers) > 0
+}
+
+func (h *sinkHandler) Handle(_ context.Context, r slog.Record) error {
+	return h.handle(r, "")
+}
+
+// handle 将记录发送给订阅者，key 为翻译前的消息 key
+func (h *sinkHandler) handle(r slog.Record, key string) error {
+	e := Entry{
+		Time:    r.Time.UnixMilli(),
+		Level:   levelName(r.Level),
+		Key:     key,
+		Message: r.Message,
+	}
+	add := func(a slog.Attr) bool {
+		if a.Key == "component" && h.prefix == "" {
+			e.Component = a.Value.String()
+			return true
+		}
+		if e.Attrs == nil {
+			e.Attrs = make(map[string]string)
+		}
+		e.Attrs[a.Key] = a.Value.String()
+		return true
+	}
+	for _, a := range h.attrs {
+		add(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		a.Key = h.prefix + a.Key
+		return add(a)
+	})
+
+	subscribersLock.RLock()
+	defer subscribersLock.RUnlock()
+	for _, fn := range subscribers {
+		fn(e)
+	}
+	return nil
+}
+
+func (h *sinkHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := &sinkHandler{prefix: h.prefix}
+	next.attrs = append(append(next.attrs, h.attrs...), prefixed(h.prefix, attrs)...)
+	return next
+}
+
+func (h *sinkHandler) WithGroup(name string) slog.Handler {
+	return &sinkHandler{attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+func prefixed(prefix string, attrs []slog.Attr) []slog.Attr {
+	if prefix == "" {
+		return attrs
+	}
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i] = slog.Attr{Key: prefix + a.Key, Value: a.Value}
+	}
+	return out
+}
+
+func levelName(l slog.Level) string {
+	switch {
+	case l < slog.LevelInfo:
+		return "debug"
+	case l < slog.LevelWarn:
+		return "info"
+	case l < slog.LevelError:
+		return "warn"
+	default:
+		return "error"
+	}
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"embed"
 
+	"ccsync-net/i18n"
+	"ccsync-net/logging"
+
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
@@ -13,12 +16,21 @@ import (
 var assets embed.FS
 
 func main() {
+	if dir, err := logging.DefaultDir(); err == nil {
+		if err := logging.Init(dir); err != nil {
+			println(i18n.T("main.logFileFailed", "err", err))
+		}
+	} else {
+		logging.Init("")
+	}
+	defer logging.Close()
+
 	// Initialize clipboard (must be on main thread)
 	if err := clipboard.Init(); err != nil {
 		println("========================================")
-		println("剪贴板初始化失败:", err.Error())
+		println(i18n.T("main.clipboardInitFailed", "err", err))
 		println("")
-		println("在 Linux 系统上，请确保已安装剪贴板工具：")
+		println(i18n.T("main.clipboardToolHint"))
 		println("  Debian/Ubuntu: sudo apt-get install xclip")
 		println("  Fedora/RHEL:   sudo dnf install xclip")
 		println("  Arch Linux:    sudo pacman -S xclip")
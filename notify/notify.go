@@ -0,0 +1,30 @@
+// Package notify 发送桌面通知。
+//
+// Linux 上通过 D-Bus 调用 org.freedesktop.Notifications，
+// macOS 使用 osascript，Windows 使用 PowerShell 显示系统通知。
+package notify
+
+import "ccsync-net/i18n"
+
+// AppName 通知中显示的程序名称
+const AppName = "CCSync Net"
+
+// ErrUnsupported 当前平台不支持桌面通知
+var ErrUnsupported = i18n.Errorf("notify.unsupported")
+
+// Send 发送一条桌面通知
+func Send(title, body string) error {
+	return send(title, body)
+}
+
+// Action 通知中的按钮
+type Action struct {
+	Key   string // 点击时传给回调的标识
+	Label string // 按钮文字
+}
+
+// SendActions 发送带按钮的桌面通知，点击按钮时以按钮的 Key 调用 onAction。
+// 目前只有 Linux 支持按钮，其他平台按普通通知显示。
+func SendActions(title, body string, actions []Action, onAction func(key string)) error {
+	return sendActions(title, body, actions, onAction)
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package notify
+
+func sendActions(title, body string, actions []Action, onAction func(key string)) error {
+	return send(title, body)
+}
@@ -0,0 +1,12 @@
+package notify
+
+import "os/exec"
+
+// notifyScript 通知内容作为参数传入，不拼接进脚本
+const notifyScript = `on run argv
+display notification (item 1 of argv) with title (item 2 of argv) subtitle (item 3 of argv)
+end run`
+
+func send(title, body string) error {
+	return exec.Command("osascript", "-e", notifyScript, body, AppName, title).Run()
+}
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusName  = "org.freedesktop.Notifications"
+	dbusPath  = "/org/freedesktop/Notifications"
+	dbusIface = "org.freedesktop.Notifications"
+)
+
+// markupEscaper 转义正文，部分通知服务会将正文解析为简单标记
+var markupEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+var (
+	// callbacks 带按钮的通知 ID 与回调，通知关闭后移除
+	callbacks     = make(map[uint32]func(key string))
+	callbacksLock sync.Mutex
+	listenOnce    sync.Once
+	listenErr     error
+)
+
+func send(title, body string) error {
+	_, err := notify(title, body, nil)
+	return err
+}
+
+func sendActions(title, body string, actions []Action, onAction func(key string)) error {
+	listenOnce.Do(func() {
+		listenErr = listen()
+	})
+	if listenErr != nil {
+		return listenErr
+	}
+
+	// actions 参数为 key、文字交替排列的列表
+	list := make([]string, 0, len(actions)*2)
+	for _, a := range actions {
+		list = append(list, a.Key, a.Label)
+	}
+	callbacksLock.Lock()
+	defer callbacksLock.Unlock()
+	id, err := notify(title, body, list)
+	if err != nil {
+		return err
+	}
+	callbacks[id] = onAction
+	return nil
+}
+
+// notify 调用 Notify 方法，返回通知 ID
+func notify(title, body string, actions []string) (uint32, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return 0, err
+	}
+	if actions == nil {
+		actions = []string{}
+	}
+	var id uint32
+	err = conn.Object(dbusName, dbusPath).Call(dbusIface+".Notify", 0,
+		AppName,                     // app_name
+		uint32(0),                   // replaces_id
+		"",                          // app_icon
+		title,                       // summary
+		markupEscaper.Replace(body), // body
+		actions,                     // actions
+		map[string]dbus.Variant{},   // hints
+		int32(-1),                   // expire_timeout，-1 由通知服务决定
+	).Store(&id)
+	return id, err
+}
+
+// listen 订阅按钮点击与通知关闭信号
+func listen() error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+	err = conn.AddMatchSignal(dbus.WithMatchObjectPath(dbusPath), dbus.WithMatchInterface(dbusIface))
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	go func() {
+		for sig := range signals {
+			if len(sig.Body) < 2 {
+				continue
+			}
+			id, ok := sig.Body[0].(uint32)
+			if !ok {
+				continue
+			}
+			switch sig.Name {
+			case dbusIface + ".ActionInvoked":
+				key, _ := sig.Body[1].(string)
+				callbacksLock.Lock()
+				fn := callbacks[id]
+				delete(callbacks, id)
+				callbacksLock.Unlock()
+				if fn != nil {
+					go fn(key)
+				}
+			case dbusIface + ".NotificationClosed":
+				callbacksLock.Lock()
+				delete(callbacks, id)
+				callbacksLock.Unlock()
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !windows
+
+package notify
+
+func send(title, body string) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,9 @@
+package notify
+
+import "syscall"
+
+func send(title, body string) error {
+	cmd := toastCommand(title, body)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: 0x08000000} // CREATE_NO_WINDOW
+	return cmd.Run()
+}
@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// toastEnv 传递通知 XML 的环境变量。内容来自其他设备，不能拼接进脚本
+const toastEnv = "CCSYNC_TOAST_XML"
+
+// powershellAppID 借用 PowerShell 的 AppUserModelID 显示通知，未注册的 ID 不会显示
+const powershellAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// toastScript 固定的 PowerShell 脚本，通知内容从环境变量读取
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml($env:` + toastEnv + `)
+$toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('` + powershellAppID + `').Show($toast)
+`
+
+// toastCommand 生成显示 Windows 通知的 PowerShell 命令
+func toastCommand(title, body string) *exec.Cmd {
+	toast := `<toast><visual><binding template="ToastGeneric"><text>` + escapeXML(title) +
+		`</text><text>` + escapeXML(body) + `</text></binding></visual></toast>`
+
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+	cmd.Env = append(os.Environ(), toastEnv+"="+toast)
+	return cmd
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
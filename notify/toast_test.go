@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestToastCommand(t *testing.T) {
+	tests := []struct {
+		title string
+		body  string
+	}{
+		{title: "plain", body: "hello"},
+		// 设备名称与内容都来自其他设备，PowerShell 把 ‘ ’ ‚ ‛ 也当作单引号
+		{title: "来自 x’); calc; (’ 的内容", body: "a’); Start-Process calc; (’b"},
+		{title: "it's ‘quoted’ ‚‛", body: "<b>&amp;</b> $(calc) `n \"x\""},
+	}
+	for _, tt := range tests {
+		cmd := toastCommand(tt.title, tt.body)
+
+		// 脚本固定，不包含通知内容
+		if script := cmd.Args[len(cmd.Args)-1]; script != toastScript {
+			t.Errorf("%q: script contains data", tt.title)
+		}
+		for _, arg := range cmd.Args {
+			if strings.Contains(arg, tt.body) || strings.Contains(arg, tt.title) {
+				t.Errorf("%q: argument contains data: %q", tt.title, arg)
+			}
+		}
+
+		var value string
+		for _, e := range cmd.Env {
+			if v, ok := strings.CutPrefix(e, toastEnv+"="); ok {
+				value = v
+			}
+		}
+		var toast struct {
+			Text []string `xml:"visual>binding>text"`
+		}
+		if err := xml.Unmarshal([]byte(value), &toast); err != nil {
+			t.Fatalf("%q: %v", tt.title, err)
+		}
+		if len(toast.Text) != 2 || toast.Text[0] != tt.title || toast.Text[1] != tt.body {
+			t.Errorf("toast text = %q, want %q %q", toast.Text, tt.title, tt.body)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"strings"
+
+	"ccsync-net/i18n"
+	"ccsync-net/logging"
+	ccsync "ccsync-net/sync"
+)
+
+// PushClipboard 将当前剪贴板内容发送给其他设备，不受同步模式与暂停状态限制。
+// 与自动同步相同，密码管理器标记的内容只在开启 syncConcealed 时发送，并标记为敏感。
+func (s *Service) PushClipboard() error {
+	cfg := s.Config()
+	content := s.clipboard.GetContent()
+	if strings.TrimSpace(content) == "" {
+		return i18n.Errorf("service.clipboardEmpty")
+	}
+	concealed := s.clipboard.IsConcealed()
+	if concealed && !cfg.SyncConcealed {
+		return i18n.Errorf("service.pushConcealed")
+	}
+	if !s.send(&cfg, content, concealed) {
+		return i18n.Errorf("service.noConnection")
+	}
+
+	s.logger().Info("service.pushed", logging.Content(content))
+	s.emitter.Emit("status", i18n.M("status.pushed"))
+	if !concealed && !s.isSensitive(content) {
+		s.addRecent(Clip{Content: content})
+	}
+	return nil
+}
+
+// ApplyLatest 将本机最近收到的远程内容写入本地剪贴板，不受同步模式与暂停状态限制。
+// 不会向服务端请求内容，只能写入已经收到的；
+// 开启了保存最近内容的服务端会在连接后立即发送该内容，因此连接后即可获取。
+func (s *Service) ApplyLatest() error {
+	s.recentLock.Lock()
+	msg := s.latest
+	s.recentLock.Unlock()
+	if msg == nil {
+		return i18n.Errorf("service.nothingToPull")
+	}
+
+	cfg := s.Config()
+	s.apply(&cfg, msg)
+	s.logger().Info("service.pulled", "source", msg.Source, logging.Content(msg.Content))
+	s.emitter.Emit("status", i18n.M("status.pulled"))
+	return nil
+}
+
+// setLatest 记录最近收到的远程内容
+func (s *Service) setLatest(msg *ccsync.Message) {
+	s.recentLock.Lock()
+	s.latest = msg
+	s.recentLock.Unlock()
+}
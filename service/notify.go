@@ -0,0 +1,96 @@
+package service
+
+import (
+	"net"
+
+	"ccsync-net/clipboard"
+	"ccsync-net/config"
+	"ccsync-net/i18n"
+	"ccsync-net/notify"
+	ccsync "ccsync-net/sync"
+)
+
+// notifyPreviewLength 通知中显示的内容最大字符数
+const notifyPreviewLength = 100
+
+// notify 在后台发送桌面通知，失败时只记录日志
+func (s *Service) notify(title, body string) {
+	go func() {
+		if err := notify.Send(title, body); err != nil {
+			s.logger().Warn("notify.failed", "err", err)
+		}
+	}()
+}
+
+// notifyClip 通知收到的远程内容，敏感内容不显示原文
+func (s *Service) notifyClip(cfg *config.Config, msg *ccsync.Message) {
+	if !cfg.NotifyClips {
+		return
+	}
+	sender := msg.Device
+	if sender == "" {
+		sender = msg.Source
+	}
+	body := i18n.T("notify.clipHidden")
+	if !msg.Sensitive && !s.isSensitive(msg.Content) {
+		body = clipboard.Preview(msg.Content, notifyPreviewLength)
+	}
+	s.notify(i18n.T("notify.clipTitle", "sender", sender), body)
+}
+
+// notifyConnection 通知与服务端的连接断开或恢复，每次断开只通知一次。
+// 主动断开时不通知。
+func (s *Service) notifyConnection(connected bool) {
+	if connected {
+		if !s.connLost.Swap(false) {
+			return
+		}
+	} else if !s.client.IsActive() || s.connLost.Swap(true) {
+		return
+	}
+
+	cfg := s.Config()
+	if !cfg.NotifyConnection {
+		return
+	}
+	endpoint := s.Status().ClientEndpoint
+	if connected {
+		s.notify(i18n.T("notify.restoredTitle"), i18n.T("notify.restored", "endpoint", endpoint))
+	} else {
+		s.notify(i18n.T("notify.lostTitle"), i18n.T("notify.lost", "endpoint", endpoint))
+	}
+}
+
+// notifyServerError 通知服务端异常停止
+func (s *Service) notifyServerError(err error) {
+	if cfg := s.Config(); cfg.NotifyConnection {
+		s.notify(i18n.T("notify.serverFailedTitle"), err.Error())
+	}
+}
+
+// notifyPeer 通知首次连接的设备。已配对设备按设备 ID 区分，其余按主机地址区分。
+func (s *Service) notifyPeer(id, name, addr string) {
+	key := id
+	if key == "" {
+		key = addr
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			key = host
+		}
+	}
+
+	s.peersLock.Lock()
+	if s.peers == nil {
+		s.peers = make(map[string]bool)
+	}
+	known := s.peers[key]
+	s.peers[key] = true
+	s.peersLock.Unlock()
+
+	if cfg := s.Config(); known || !cfg.NotifyPeers {
+		return
+	}
+	if name == "" {
+		name = i18n.T("notify.unknownDevice")
+	}
+	s.notify(i18n.T("notify.peerTitle"), i18n.T("notify.peer", "name", name, "addr", addr))
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"time"
+
+	"ccsync-net/config"
+	"ccsync-net/i18n"
+	ccsync "ccsync-net/sync"
+)
+
+// SetPairing 开启或关闭服务端的配对模式
+func (s *Service) SetPairing(enabled bool) {
+	s.server.SetPairing(enabled)
+	if enabled {
+		s.logger().Info("pairing.enabled")
+	} else {
+		s.logger().Info("pairing.disabled")
+	}
+	s.emitter.Emit("pair:mode", enabled)
+}
+
+// RespondPairing 批准或拒绝配对请求
+func (s *Service) RespondPairing(id string, approve bool) error {
+	return s.server.RespondPairing(id, approve)
+}
+
+// PairedDevices 返回已配对的设备
+func (s *Service) PairedDevices() []config.PairedDevice {
+	return s.Config().PairedDevices
+}
+
+// RevokeDevice 撤销设备的配对，已连接的该设备会被断开，并从自动接受列表中移除
+func (s *Service) RevokeDevice(id string) error {
+	return s.UpdateConfig(func(cfg *config.Config) {
+		var kept []config.PairedDevice
+		for _, d := range cfg.PairedDevices {
+			if d.ID != id {
+				kept = append(kept, d)
+			}
+		}
+		cfg.PairedDevices = kept
+		cfg.AutoAccept = slices.DeleteFunc(cfg.AutoAccept, func(a string) bool { return a == id })
+	})
+}
+
+// PairWith 向服务端请求配对，成功后将获得的设备凭据保存为名为 name 的配置方案。
+// 等待服务端确认期间通过 "pair:code" 事件发送校验码供用户核对。
+func (s *Service) PairWith(addr, name string) error {
+	cfg := s.Config()
+	if name == "" {
+		name = addr
+	}
+
+	s.logger().Info("pairing.requesting", "addr", addr)
+	token, err := ccsync.Pair(ccsync.Endpoint{Address: addr}, cfg.DeviceID, cfg.DeviceName, func(code string) {
+		s.emitter.Emit("pair:code", code)
+	})
+	if err != nil {
+		s.logger().Warn("pairing.failed", "addr", addr, "err", err)
+		return err
+	}
+
+	s.logger().Info("pairing.succeeded", "profile", name)
+	return s.UpdateConfig(func(cfg *config.Config) {
+		profile := config.Profile{Name: name, Address: addr, Secret: token}
+		if p, ok := cfg.FindProfile(name); ok {
+			profile.TLSPin, profile.Channel = p.TLSPin, p.Channel
+		}
+		saveProfile(cfg, profile)
+	})
+}
+
+// ConnectionURI 生成本机服务端的连接 URI，包含地址、认证密钥和证书指纹。
+// host 为空时自动选择一个非回环地址。
+func (s *Service) ConnectionURI(host string) (string, error) {
+	cfg := s.Config()
+	if host == "" {
+		var err error
+		if host, err = advertiseHost(cfg.BindAddresses); err != nil {
+			return "", err
+		}
+	}
+
+	ep := ccsync.Endpoint{
+		Name:    cfg.DeviceName,
+		Address: net.JoinHostPort(host, strconv.Itoa(cfg.ServerPort)),
+		Secret:  cfg.ServerSecret,
+		TLSPin:  cfg.ServerTLSPin,
+	}
+	return ep.URI(), nil
+}
+
+// ImportURI 导入连接 URI，保存为配置方案并设为当前方案，返回方案名称
+func (s *Service) ImportURI(uri string) (string, error) {
+	ep, err := ccsync.ParseURI(uri)
+	if err != nil {
+		return "", err
+	}
+
+	name := ep.Name
+	if name == "" {
+		name = ep.Address
+	}
+	err = s.UpdateConfig(func(cfg *config.Config) {
+		name = importProfile(cfg, config.Profile{
+			Name:    name,
+			Address: ep.Address,
+			Secret:  ep.Secret,
+			TLSPin:  ep.TLSPin,
+			Channel: ep.Channel,
+		})
+		cfg.ActiveProfile = name
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.logger().Info("pairing.imported", "profile", name)
+	return name, nil
+}
+
+// saveProfile 添加配置方案，同名方案将被替换
+func saveProfile(cfg *config.Config, profile config.Profile) {
+	for i, p := range cfg.Profiles {
+		if p.Name == profile.Name {
+			cfg.Profiles[i] = profile
+			return
+		}
+	}
+	cfg.Profiles = append(cfg.Profiles, profile)
+}
+
+// importProfile 添加导入的配置方案，返回实际使用的名称。
+// 名称来自 URI，同名方案的地址与证书指纹相同时才更新，否则在名称后加序号另存，不覆盖已有方案。
+func importProfile(cfg *config.Config, profile config.Profile) string {
+	name := profile.Name
+	for n := 2; ; n++ {
+		p, ok := cfg.FindProfile(profile.Name)
+		if !ok || (p.Address == profile.Address && p.TLSPin == profile.TLSPin) {
+			break
+		}
+		profile.Name = fmt.Sprintf("%s (%d)", name, n)
+	}
+	saveProfile(cfg, profile)
+	return profile.Name
+}
+
+// advertiseHost 选择供其他设备连接的本机地址，优先使用监听范围内的 IPv4 地址
+func advertiseHost(bind []string) (string, error) {
+	if len(bind) > 0 {
+		addrs, err := ccsync.ResolveBindAddresses(bind, 0)
+		if err != nil {
+			return "", err
+		}
+		for _, addr := range addrs {
+			host, _, _ := net.SplitHostPort(addr)
+			if ip := net.ParseIP(host); ip != nil && !ip.IsLoopback() {
+				return host, nil
+			}
+		}
+		host, _, _ := net.SplitHostPort(addrs[0])
+		return host, nil
+	}
+
+	addrs, err := ccsync.LocalAddresses()
+	if err != nil {
+		return "", err
+	}
+	var fallback string
+	for _, a := range addrs {
+		if a.Loopback {
+			continue
+		}
+		if !a.IPv6 {
+			return a.IP, nil
+		}
+		if fallback == "" {
+			fallback = a.IP
+		}
+	}
+	if fallback == "" {
+		return "", i18n.Errorf("pairing.noAddress")
+	}
+	return fallback, nil
+}
+
+// savePairedDevice 保存服务端新配对的设备
+func (s *Service) savePairedDevice(d ccsync.PairedDevice) {
+	err := s.UpdateConfig(func(cfg *config.Config) {
+		device := config.PairedDevice{
+			ID:        d.ID,
+			Name:      d.Name,
+			TokenHash: d.TokenHash,
+			PairedAt:  time.Now().UnixMilli(),
+		}
+		for i, existing := range cfg.PairedDevices {
+			if existing.ID == d.ID {
+				cfg.PairedDevices[i] = device
+				return
+			}
+		}
+		cfg.PairedDevices = append(cfg.PairedDevices, device)
+	})
+	if err != nil {
+		s.logger().Error("pairing.saveDeviceFailed", "device", d.Name, "err", err)
+	}
+}
+
+// pairedDevices 将配置中的已配对设备转换为服务端使用的格式
+func pairedDevices(cfg *config.Config) []ccsync.PairedDevice {
+	devices := make([]ccsync.PairedDevice, 0, len(cfg.PairedDevices))
+	for _, d := range cfg.PairedDevices {
+		devices = append(devices, ccsync.PairedDevice{ID: d.ID, Name: d.Name, TokenHash: d.TokenHash})
+	}
+	return devices
+}
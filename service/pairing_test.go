@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+
+	"ccsync-net/config"
+)
+
+func TestImportProfile(t *testing.T) {
+	existing := []config.Profile{
+		{Name: "home", Address: "10.0.0.1:8765", Secret: "old", TLSPin: "pin"},
+		{Name: "work", Address: "10.0.0.2:8765"},
+		{Name: "work (2)", Address: "10.0.0.3:8765"},
+	}
+	tests := []struct {
+		name    string
+		profile config.Profile
+		want    string
+		count   int
+	}{
+		{name: "new", profile: config.Profile{Name: "laptop", Address: "10.0.0.9:8765"}, want: "laptop", count: 4},
+		{name: "same server", profile: config.Profile{Name: "home", Address: "10.0.0.1:8765", Secret: "new", TLSPin: "pin"}, want: "home", count: 3},
+		{name: "other address", profile: config.Profile{Name: "home", Address: "10.0.0.8:8765"}, want: "home (2)", count: 4},
+		{name: "other pin", profile: config.Profile{Name: "home", Address: "10.0.0.1:8765", TLSPin: "evil"}, want: "home (2)", count: 4},
+		{name: "suffix taken", profile: config.Profile{Name: "work", Address: "10.0.0.8:8765"}, want: "work (3)", count: 4},
+		{name: "suffix same server", profile: config.Profile{Name: "work", Address: "10.0.0.3:8765"}, want: "work (2)", count: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Profiles = append([]config.Profile(nil), existing...)
+
+			if got := importProfile(cfg, tt.profile); got != tt.want {
+				t.Fatalf("importProfile = %q, want %q", got, tt.want)
+			}
+			if len(cfg.Profiles) != tt.count {
+				t.Fatalf("profiles = %+v, want %d", cfg.Profiles, tt.count)
+			}
+			p, _ := cfg.FindProfile(tt.want)
+			if p.Address != tt.profile.Address || p.Secret != tt.profile.Secret || p.TLSPin != tt.profile.TLSPin {
+				t.Errorf("saved %+v, want %+v", p, tt.profile)
+			}
+			// 其他方案的凭据保持不变
+			for _, old := range existing {
+				if old.Name == tt.want {
+					continue
+				}
+				if p, _ := cfg.FindProfile(old.Name); p != old {
+					t.Errorf("profile %q = %+v, want %+v", old.Name, p, old)
+				}
+			}
+		})
+	}
+}
+
+func TestRevokeDevice(t *testing.T) {
+	ts := newTestService(t, func(cfg *config.Config) {
+		cfg.PairedDevices = []config.PairedDevice{{ID: "dev-1", Name: "phone"}, {ID: "dev-2", Name: "laptop"}}
+		cfg.AutoAccept = []string{"dev-1", "dev-2"}
+	})
+
+	if err := ts.RevokeDevice("dev-1"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := ts.Config()
+	if len(cfg.PairedDevices) != 1 || cfg.PairedDevices[0].ID != "dev-2" {
+		t.Errorf("PairedDevices = %+v", cfg.PairedDevices)
+	}
+	if len(cfg.AutoAccept) != 1 || cfg.AutoAccept[0] != "dev-2" {
+		t.Errorf("AutoAccept = %v", cfg.AutoAccept)
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"time"
+
+	"ccsync-net/config"
+)
+
+// PauseState 暂停状态
+type PauseState struct {
+	Send    bool  `json:"send"`    // 暂停发送
+	Receive bool  `json:"receive"` // 暂停接收
+	Until   int64 `json:"until"`   // 自动恢复时间 (毫秒时间戳)，0 表示不自动恢复
+}
+
+// SetPaused 设置暂停状态，d 大于 0 时在 d 之后自动恢复
+func (s *Service) SetPaused(send, receive bool, d time.Duration) {
+	s.pauseLock.Lock()
+	if s.resumeTimer != nil {
+		s.resumeTimer.Stop()
+		s.resumeTimer = nil
+	}
+
+	s.pause = PauseState{Send: send, Receive: receive}
+	if d > 0 && (send || receive) {
+		s.pause.Until = time.Now().Add(d).UnixMilli()
+		var timer *time.Timer
+		timer = time.AfterFunc(d, func() {
+			s.pauseLock.Lock()
+			if s.resumeTimer != timer {
+				s.pauseLock.Unlock()
+				return
+			}
+			s.resumeTimer = nil
+			s.pause = PauseState{}
+			s.pauseLock.Unlock()
+
+			s.logger().Info("pause.expired")
+			s.emitter.Emit("sync:paused", PauseState{})
+		})
+		s.resumeTimer = timer
+	}
+	state := s.pause
+	s.pauseLock.Unlock()
+
+	switch {
+	case send && receive:
+		s.logger().Info("pause.paused")
+	case send:
+		s.logger().Info("pause.sendPaused")
+	case receive:
+		s.logger().Info("pause.receivePaused")
+	default:
+		s.logger().Info("pause.resumed")
+	}
+	s.emitter.Emit("sync:paused", state)
+}
+
+// TogglePause 暂停时恢复同步，否则暂停收发
+func (s *Service) TogglePause() {
+	state := s.Paused()
+	s.SetPaused(!state.Send && !state.Receive, !state.Send && !state.Receive, 0)
+}
+
+// Paused 返回当前暂停状态
+func (s *Service) Paused() PauseState {
+	s.pauseLock.Lock()
+	defer s.pauseLock.Unlock()
+	return s.pause
+}
+
+// canSend 检查同步模式与暂停状态是否允许发送
+func (s *Service) canSend(cfg *config.Config) (bool, string) {
+	switch cfg.SyncMode {
+	case config.SyncReceiveOnly:
+		return false, "pause.skipSendReceiveOnly"
+	case config.SyncDisabled:
+		return false, "pause.skipSendDisabled"
+	case config.SyncManual:
+		return false, "pause.skipSendManual"
+	}
+	if s.Paused().Send {
+		return false, "pause.skipSendPaused"
+	}
+	return true, ""
+}
+
+// canReceive 检查同步模式与暂停状态是否允许写入本地剪贴板
+func (s *Service) canReceive(cfg *config.Config) (bool, string) {
+	switch cfg.SyncMode {
+	case config.SyncSendOnly:
+		return false, "pause.skipReceiveSendOnly"
+	case config.SyncDisabled:
+		return false, "pause.skipReceiveDisabled"
+	case config.SyncManual:
+		return false, "pause.skipReceiveManual"
+	}
+	if s.Paused().Receive {
+		return false, "pause.skipReceivePaused"
+	}
+	return true, ""
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"ccsync-net/clipboard"
+	"ccsync-net/config"
+	"ccsync-net/i18n"
+	"ccsync-net/logging"
+	"ccsync-net/notify"
+	ccsync "ccsync-net/sync"
+)
+
+// PendingClipsSize 等待确认的内容最多保留条数，超出时丢弃最早的
+const PendingClipsSize = 10
+
+// PendingClip 等待确认后才写入本地剪贴板的远程内容
+type PendingClip struct {
+	ID        string `json:"id"`
+	Content   string `json:"content"`
+	Sender    string `json:"sender"`   // 发送方设备名称，旧版本客户端为来源标识
+	DeviceID  string `json:"deviceId"` // 服务端通过设备凭据认证的发送方设备 ID，为空时不能设为自动接受
+	Sensitive bool   `json:"sensitive"`
+	Time      int64  `json:"time"` // 毫秒时间戳
+
+	msg *ccsync.Message
+}
+
+// PendingClips 返回等待确认的内容，最新的在前
+func (s *Service) PendingClips() []PendingClip {
+	s.pendingLock.Lock()
+	defer s.pendingLock.Unlock()
+	return append([]PendingClip(nil), s.pending...)
+}
+
+// AcceptClip 接受等待确认的内容并写入本地剪贴板。
+// always 为 true 时将发送方加入自动接受列表，之后的内容不再需要确认；
+// 只有通过设备凭据认证的发送方可以加入，否则不做任何处理并返回错误。
+func (s *Service) AcceptClip(id string, always bool) error {
+	if p, ok := s.findPending(id); ok && always && p.DeviceID == "" {
+		return i18n.Errorf("service.senderUnverified", "sender", p.Sender)
+	}
+
+	p, ok := s.takePending(id)
+	if !ok {
+		return i18n.Errorf("service.pendingNotFound")
+	}
+
+	cfg := s.Config()
+	s.apply(&cfg, p.msg)
+	s.logger().Info("service.accepted", "sender", p.Sender, logging.Content(p.Content))
+
+	if always {
+		return s.UpdateConfig(func(c *config.Config) {
+			if !slices.Contains(c.AutoAccept, p.DeviceID) {
+				c.AutoAccept = append(c.AutoAccept, p.DeviceID)
+			}
+		})
+	}
+	return nil
+}
+
+// RejectClip 丢弃等待确认的内容
+func (s *Service) RejectClip(id string) error {
+	p, ok := s.takePending(id)
+	if !ok {
+		return i18n.Errorf("service.pendingNotFound")
+	}
+	s.logger().Info("service.rejected", "sender", p.Sender, logging.Content(p.Content))
+	return nil
+}
+
+// RejectAllClips 丢弃所有等待确认的内容
+func (s *Service) RejectAllClips() {
+	s.pendingLock.Lock()
+	s.pending = nil
+	s.pendingLock.Unlock()
+
+	s.emitter.Emit("clips:pending", []PendingClip{})
+}
+
+// needsConfirm 检查远程内容是否需要确认后才能写入。
+// 自动接受只按服务端 (本机或上游) 认证的设备 ID 判断，不信任消息中自报的来源与名称。
+func needsConfirm(cfg *config.Config, msg *ccsync.Message) bool {
+	if !cfg.ConfirmReceive {
+		return false
+	}
+	return msg.AuthDevice == "" || !slices.Contains(cfg.AutoAccept, msg.AuthDevice)
+}
+
+// addPending 将远程内容加入等待确认的队列，并发送带接受、拒绝按钮的通知
+func (s *Service) addPending(cfg *config.Config, msg *ccsync.Message) {
+	sender := msg.Device
+	if sender == "" {
+		sender = msg.Source
+	}
+
+	s.pendingLock.Lock()
+	s.pendingSeq++
+	p := PendingClip{
+		ID:        strconv.FormatUint(s.pendingSeq, 10),
+		Content:   msg.Content,
+		Sender:    sender,
+		DeviceID:  msg.AuthDevice,
+		Sensitive: msg.Sensitive || s.isSensitive(msg.Content),
+		Time:      time.Now().UnixMilli(),
+		msg:       msg,
+	}
+	// 相同内容只保留最新的一条
+	clips := []PendingClip{p}
+	for _, old := range s.pending {
+		if old.Content != p.Content && len(clips) < PendingClipsSize {
+			clips = append(clips, old)
+		}
+	}
+	s.pending = clips
+	clips = append([]PendingClip(nil), clips...)
+	s.pendingLock.Unlock()
+
+	s.logger().Info("service.pending", "sender", sender)
+	s.emitter.Emit("clips:pending", clips)
+
+	body := i18n.T("notify.clipHidden")
+	if !p.Sensitive {
+		body = clipboard.Preview(p.Content, notifyPreviewLength)
+	}
+	actions := []notify.Action{
+		{Key: "accept", Label: i18n.T("notify.accept")},
+		{Key: "reject", Label: i18n.T("notify.reject")},
+	}
+	go func() {
+		err := notify.SendActions(i18n.T("notify.pendingTitle", "sender", sender), body, actions, func(key string) {
+			// 点击通知本身或关闭通知时保留在队列中
+			var err error
+			switch key {
+			case "accept":
+				err = s.AcceptClip(p.ID, false)
+			case "reject":
+				err = s.RejectClip(p.ID)
+			}
+			if err != nil {
+				s.logger().Warn("service.confirmFailed", "err", err)
+			}
+		})
+		if err != nil {
+			s.logger().Warn("notify.failed", "err", err)
+		}
+	}()
+}
+
+// findPending 查找队列中的指定内容
+func (s *Service) findPending(id string) (PendingClip, bool) {
+	s.pendingLock.Lock()
+	defer s.pendingLock.Unlock()
+	i := slices.IndexFunc(s.pending, func(p PendingClip) bool { return p.ID == id })
+	if i < 0 {
+		return PendingClip{}, false
+	}
+	return s.pending[i], true
+}
+
+// takePending 从队列中取出指定内容
+func (s *Service) takePending(id string) (PendingClip, bool) {
+	s.pendingLock.Lock()
+	i := slices.IndexFunc(s.pending, func(p PendingClip) bool { return p.ID == id })
+	if i < 0 {
+		s.pendingLock.Unlock()
+		return PendingClip{}, false
+	}
+	p := s.pending[i]
+	s.pending = slices.Delete(s.pending, i, i+1)
+	clips := append([]PendingClip(nil), s.pending...)
+	s.pendingLock.Unlock()
+
+	s.emitter.Emit("clips:pending", clips)
+	return p, true
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+
+	"ccsync-net/config"
+	ccsync "ccsync-net/sync"
+)
+
+func TestNeedsConfirm(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ConfirmReceive = true
+	cfg.AutoAccept = []string{"dev-1", "laptop"}
+
+	tests := []struct {
+		name string
+		msg  ccsync.Message
+		want bool
+	}{
+		{name: "authenticated", msg: ccsync.Message{AuthDevice: "dev-1"}, want: false},
+		{name: "other device", msg: ccsync.Message{AuthDevice: "dev-2"}, want: true},
+		{name: "self-reported origin", msg: ccsync.Message{Origin: "dev-1"}, want: true},
+		{name: "self-reported name", msg: ccsync.Message{Device: "laptop"}, want: true},
+		{name: "origin mismatch", msg: ccsync.Message{AuthDevice: "dev-2", Origin: "dev-1", Device: "laptop"}, want: true},
+	}
+	for _, tt := range tests {
+		if got := needsConfirm(cfg, &tt.msg); got != tt.want {
+			t.Errorf("%s: needsConfirm = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	cfg.ConfirmReceive = false
+	if needsConfirm(cfg, &ccsync.Message{}) {
+		t.Error("needsConfirm with confirmReceive off = true")
+	}
+}
+
+func TestAcceptAlways(t *testing.T) {
+	ts := newTestService(t, func(cfg *config.Config) { cfg.ConfirmReceive = true })
+
+	ts.handleRemote(&ccsync.Message{ID: "1", Origin: "dev-1", Device: "phone", Content: "a"}, fromClient, nil)
+	ts.handleRemote(&ccsync.Message{ID: "2", Origin: "dev-2", AuthDevice: "dev-2", Content: "b"}, fromServer, nil)
+	pending := ts.PendingClips()
+	if len(pending) != 2 || ts.clipboard.content != "" {
+		t.Fatalf("pending = %+v, clipboard %q", pending, ts.clipboard.content)
+	}
+
+	// 未认证的发送方不能设为自动接受，内容保留在队列中
+	if err := ts.AcceptClip(pending[1].ID, true); err == nil {
+		t.Error("AcceptClip(always) for unauthenticated sender succeeded")
+	}
+	if ts.clipboard.content != "" || len(ts.PendingClips()) != 2 || len(ts.Config().AutoAccept) != 0 {
+		t.Fatalf("clipboard %q, pending %d, autoAccept %v", ts.clipboard.content, len(ts.PendingClips()), ts.Config().AutoAccept)
+	}
+	if err := ts.AcceptClip(pending[1].ID, false); err != nil || ts.clipboard.content != "a" {
+		t.Fatalf("AcceptClip = %v, clipboard %q", err, ts.clipboard.content)
+	}
+
+	if err := ts.AcceptClip(pending[0].ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := ts.Config().AutoAccept; len(got) != 1 || got[0] != "dev-2" {
+		t.Fatalf("autoAccept = %v", got)
+	}
+	ts.handleRemote(&ccsync.Message{ID: "3", AuthDevice: "dev-2", Content: "c"}, fromServer, nil)
+	if ts.clipboard.content != "c" || len(ts.PendingClips()) != 0 {
+		t.Errorf("clipboard %q, pending %d", ts.clipboard.content, len(ts.PendingClips()))
+	}
+}
+
+func TestAutoAcceptRelayed(t *testing.T) {
+	ts := newTestService(t, func(cfg *config.Config) {
+		cfg.Mode = config.ModeClient
+		cfg.ConfirmReceive = true
+		cfg.AutoAccept = []string{"dev-1"}
+	})
+
+	// 客户端收到上游服务端认证过的发送方
+	ts.handleRemote(&ccsync.Message{ID: "1", AuthDevice: "dev-1", Content: "a"}, fromClient, nil)
+	if ts.clipboard.content != "a" || len(ts.PendingClips()) != 0 {
+		t.Fatalf("clipboard %q, pending %d", ts.clipboard.content, len(ts.PendingClips()))
+	}
+
+	ts.handleRemote(&ccsync.Message{ID: "2", Origin: "dev-1", Content: "b"}, fromClient, nil)
+	if ts.clipboard.content != "a" || len(ts.PendingClips()) != 1 {
+		t.Fatalf("clipboard %q, pending %d", ts.clipboard.content, len(ts.PendingClips()))
+	}
+}
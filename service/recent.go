@@ -0,0 +1,53 @@
+package service
+
+import (
+	"time"
+
+	"ccsync-net/i18n"
+)
+
+// RecentClipsSize 保留的最近内容条数
+const RecentClipsSize = 10
+
+// Clip 最近复制或收到的一条内容
+type Clip struct {
+	Content string `json:"content"`
+	Source  string `json:"source"` // 来源设备名称或标识，本机复制时为空
+	Remote  bool   `json:"remote"` // 是否来自其他设备
+	Time    int64  `json:"time"`   // 毫秒时间戳
+}
+
+// RecentClips 返回最近的内容，最新的在前。敏感内容不会记录。
+func (s *Service) RecentClips() []Clip {
+	s.recentLock.Lock()
+	defer s.recentLock.Unlock()
+	return append([]Clip(nil), s.recent...)
+}
+
+// CopyRecent 将第 i 条最近内容重新写入本地剪贴板
+func (s *Service) CopyRecent(i int) error {
+	clips := s.RecentClips()
+	if i < 0 || i >= len(clips) {
+		return i18n.Errorf("service.clipNotFound")
+	}
+	s.clipboard.SetContent(clips[i].Content)
+	s.addRecent(clips[i])
+	return nil
+}
+
+// addRecent 记录一条内容，相同内容只保留最新的一条
+func (s *Service) addRecent(c Clip) {
+	c.Time = time.Now().UnixMilli()
+
+	s.recentLock.Lock()
+	clips := []Clip{c}
+	for _, old := range s.recent {
+		if old.Content != c.Content && len(clips) < RecentClipsSize {
+			clips = append(clips, old)
+		}
+	}
+	s.recent = clips
+	s.recentLock.Unlock()
+
+	s.emitter.Emit("clips:recent", clips)
+}
@@ -0,0 +1,697 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ccsync-net/clipboard"
+	"ccsync-net/config"
+	"ccsync-net/i18n"
+	"ccsync-net/logging"
+	ccsync "ccsync-net/sync"
+)
+
+// Emitter 事件输出接口，由界面层实现
+type Emitter interface {
+	// Emit 发送事件给界面
+	Emit(event string, data ...interface{})
+}
+
+// Server 本机服务端，由 *ccsync.Server 实现
+type Server interface {
+	Start(port int) error
+	Stop() error
+	IsRunning() bool
+	GetClientCount() int
+	Broadcast(msg *ccsync.Message)
+	SetSecret(secret string)
+	SetDeviceID(id string)
+	SetAPIToken(token string)
+	SetRequirePairing(require bool)
+	SetBindAddresses(entries []string)
+	SetLimits(l ccsync.Limits) error
+	SetDevices(devices []ccsync.PairedDevice)
+	SetPairing(enabled bool)
+	RespondPairing(id string, approve bool) error
+	SetHandlers(h ccsync.ServerHandlers)
+}
+
+// Client 连接上游服务端的客户端，由 *ccsync.Client 实现
+type Client interface {
+	ConnectEndpoints(endpoints []ccsync.Endpoint) error
+	ReconnectEndpoints(endpoints []ccsync.Endpoint) error
+	Disconnect()
+	IsActive() bool
+	IsConnected() bool
+	Endpoint() ccsync.Endpoint
+	SendMessage(msg *ccsync.Message) error
+	SetHandlers(h ccsync.ClientHandlers)
+}
+
+// Mesh mesh 模式的节点网络，由 *ccsync.Mesh 实现
+type Mesh interface {
+	Start(port, discoveryPort int, secret string, static []string) error
+	Stop()
+	IsRunning() bool
+	ConnectedCount() int
+	Send(msg *ccsync.Message, except *ccsync.Client)
+	Announce(id, addr string)
+	SetHandlers(h ccsync.MeshHandlers)
+}
+
+// Clipboard 本地剪贴板，由 *clipboard.Monitor 实现
+type Clipboard interface {
+	Start() error
+	Stop()
+	GetContent() string
+	IsConcealed() bool
+	SetContent(content string)
+	SetContentWithExpiry(content string, ttl time.Duration, restore bool)
+	SetSyncConcealed(v bool)
+	SetHandlers(h clipboard.MonitorHandlers)
+}
+
+// Service 同步编排服务，负责在剪贴板与网络之间转发内容
+type Service struct {
+	cfg       *config.Config
+	sensitive []*regexp.Regexp // 编译后的 SensitivePatterns
+	cfgLock   sync.RWMutex
+
+	server    Server
+	client    Client
+	mesh      Mesh
+	clipboard Clipboard
+	seen      *ccsync.SeenCache
+	emitter   Emitter
+
+	pause       PauseState
+	resumeTimer *time.Timer
+	pauseLock   sync.Mutex
+
+	recent     []Clip
+	latest     *ccsync.Message // 最近收到的远程内容，包括未写入剪贴板的
+	recentLock sync.Mutex
+
+	pending     []PendingClip // 等待确认的远程内容，最新的在前
+	pendingSeq  uint64
+	pendingLock sync.Mutex
+
+	serverErr  error
+	statusLock sync.Mutex
+
+	connLost  atomic.Bool     // 连接断开且已通知，恢复时再通知一次
+	peers     map[string]bool // 已通知过的设备
+	peersLock sync.Mutex
+
+	stopWatch context.CancelFunc
+}
+
+// New 创建同步编排服务
+func New(cfg *config.Config, server Server, client Client, mesh Mesh, monitor Clipboard, emitter Emitter) *Service {
+	s := &Service{
+		cfg:       cfg,
+		server:    server,
+		client:    client,
+		mesh:      mesh,
+		clipboard: monitor,
+		seen:      ccsync.NewSeenCache(ccsync.DefaultSeenCacheSize),
+		emitter:   emitter,
+		sensitive: compilePatterns(cfg.SensitivePatterns),
+	}
+	i18n.SetLocale(cfg.Language)
+	logging.SetDebug(cfg.Debug)
+	logging.SetLogContent(cfg.LogContent)
+	s.clipboard.SetSyncConcealed(cfg.SyncConcealed)
+	s.server.SetSecret(cfg.ServerSecret)
+	s.server.SetDeviceID(cfg.DeviceID)
+	s.server.SetAPIToken(cfg.APIToken)
+	s.server.SetRequirePairing(cfg.RequirePairing)
+	s.server.SetBindAddresses(cfg.BindAddresses)
+	if err := s.server.SetLimits(limits(cfg)); err != nil {
+		s.logger().Error("service.limitsFailed", "err", err)
+	}
+	s.server.SetDevices(pairedDevices(cfg))
+	s.initCallbacks()
+	return s
+}
+
+// Config 返回当前配置的副本
+func (s *Service) Config() config.Config {
+	s.cfgLock.RLock()
+	defer s.cfgLock.RUnlock()
+	return *s.cfg.Clone()
+}
+
+// UpdateConfig 修改、校验并保存配置，校验失败时不做任何修改
+func (s *Service) UpdateConfig(update func(cfg *config.Config)) error {
+	s.cfgLock.Lock()
+	next := s.cfg.Clone()
+	update(next)
+	if err := next.Validate(); err != nil {
+		s.cfgLock.Unlock()
+		s.emitter.Emit("config:error", err.Error())
+		return err
+	}
+
+	changes := config.Diff(s.cfg, next)
+	s.cfg = next
+	err := s.cfg.Save()
+	s.cfgLock.Unlock()
+
+	s.applyChanges(changes, *next.Clone())
+	return err
+}
+
+// reloadConfig 应用从文件重新加载的配置
+func (s *Service) reloadConfig(cfg *config.Config, err error) {
+	if err != nil {
+		s.logger().Error("service.reloadFailed", "err", err)
+		s.emitter.Emit("config:error", err.Error())
+		return
+	}
+
+	s.cfgLock.Lock()
+	changes := config.Diff(s.cfg, cfg)
+	s.cfg = cfg.Clone()
+	s.cfgLock.Unlock()
+
+	if len(changes) > 0 {
+		s.logger().Info("service.configFileChanged")
+	}
+	s.applyChanges(changes, *cfg)
+}
+
+// applyChanges 根据配置变化调整运行中的服务端或客户端
+func (s *Service) applyChanges(changes []config.Change, cfg config.Config) {
+	if len(changes) == 0 {
+		return
+	}
+
+	if config.Has(changes, "language") {
+		i18n.SetLocale(cfg.Language)
+		s.emitter.Emit("i18n:changed", i18n.Locale())
+	}
+
+	for _, c := range changes {
+		s.logger().Info("service.configUpdated", "field", c.Field)
+	}
+	s.emitter.Emit("config:changed", cfg)
+
+	logging.SetDebug(cfg.Debug)
+	logging.SetLogContent(cfg.LogContent)
+	s.clipboard.SetSyncConcealed(cfg.SyncConcealed)
+	s.server.SetSecret(cfg.ServerSecret)
+	s.server.SetAPIToken(cfg.APIToken)
+	s.server.SetRequirePairing(cfg.RequirePairing)
+	s.server.SetBindAddresses(cfg.BindAddresses)
+	if err := s.server.SetLimits(limits(&cfg)); err != nil {
+		s.logger().Error("service.limitsFailed", "err", err)
+		s.emitter.Emit("config:error", err.Error())
+	}
+	if config.Has(changes, "pairedDevices") {
+		s.server.SetDevices(pairedDevices(&cfg))
+	}
+	if config.Has(changes, "sensitivePatterns") {
+		sensitive := compilePatterns(cfg.SensitivePatterns)
+		s.cfgLock.Lock()
+		s.sensitive = sensitive
+		s.cfgLock.Unlock()
+	}
+
+	if config.Has(changes, "mode") {
+		// 离开原模式时停止对应的服务
+		if !cfg.RunsServer() && s.server.IsRunning() {
+			s.StopServer()
+		}
+		if !cfg.RunsClient() && s.client.IsActive() {
+			s.Disconnect()
+		}
+		if cfg.Mode != config.ModeMesh && s.mesh.IsRunning() {
+			s.StopMesh()
+		}
+	}
+
+	if (config.Has(changes, "serverPort") || config.Has(changes, "bindAddresses")) &&
+		cfg.RunsServer() && s.server.IsRunning() {
+		s.logger().Info("service.bindChanged")
+		s.StopServer()
+		s.StartServer(cfg.ServerPort)
+	}
+
+	if cfg.Mode == config.ModeMesh && s.mesh.IsRunning() &&
+		(config.Has(changes, "serverPort") || config.Has(changes, "bindAddresses") || config.Has(changes, "meshPeers") ||
+			config.Has(changes, "discoveryPort") || config.Has(changes, "serverSecret")) {
+		s.logger().Info("service.meshChanged")
+		s.StopMesh()
+		s.StartMesh()
+	}
+
+	if cfg.RunsClient() && s.client.IsActive() {
+		if config.Has(changes, "activeProfile") ||
+			(config.Has(changes, "profiles") && s.client.Endpoint().Name != "") {
+			// 切换了方案，或当前连接使用的方案被修改
+			s.logger().Info("service.profileChanged")
+			s.ConnectConfigured()
+		} else if config.Has(changes, "serverAddress") && cfg.ActiveProfile == "" {
+			s.logger().Info("service.addressChanged")
+			s.ConnectConfigured()
+		}
+	}
+}
+
+// StartServer 启动服务端
+func (s *Service) StartServer(port int) error {
+	s.emitter.Emit("status", i18n.M("status.serverStarting"))
+	if err := s.server.Start(port); err != nil {
+		s.setServerError(err)
+		s.emitter.Emit("status", i18n.M("status.serverStartFailed", "err", err))
+		return err
+	}
+	s.setServerError(nil)
+	s.emitter.Emit("status", i18n.M("status.serverRunning", "port", port))
+	s.emitter.Emit("server:running", true)
+	return nil
+}
+
+// StopServer 停止服务端
+func (s *Service) StopServer() {
+	s.server.Stop()
+	s.setServerError(nil)
+	s.emitter.Emit("status", i18n.M("status.serverStopped"))
+	s.emitter.Emit("server:running", false)
+}
+
+// Connect 连接服务端，地址与某个配置方案相同时使用该方案的认证设置
+func (s *Service) Connect(addr string) error {
+	s.emitter.Emit("status", i18n.M("status.connecting", "addr", addr))
+
+	cfg := s.Config()
+	for _, p := range cfg.Profiles {
+		if p.Address == addr {
+			return s.connectEndpoints([]ccsync.Endpoint{endpoint(p, cfg.DeviceID)})
+		}
+	}
+	return s.connectEndpoints([]ccsync.Endpoint{{Address: addr}})
+}
+
+// ConnectProfile 使用指定配置方案连接，name 为空时按优先级自动选择可连接的方案
+func (s *Service) ConnectProfile(name string) error {
+	cfg := s.Config()
+
+	var endpoints []ccsync.Endpoint
+	if name == "" {
+		for _, p := range cfg.Profiles {
+			endpoints = append(endpoints, endpoint(p, cfg.DeviceID))
+		}
+		s.emitter.Emit("status", i18n.M("status.selectingServer"))
+	} else {
+		p, ok := cfg.FindProfile(name)
+		if !ok {
+			return i18n.Errorf("config.profileNotFound", "value", name)
+		}
+		endpoints = append(endpoints, endpoint(p, cfg.DeviceID))
+		s.emitter.Emit("status", i18n.M("status.connecting", "addr", p.Name))
+	}
+
+	return s.connectEndpoints(endpoints)
+}
+
+// connectEndpoints 连接指定的服务端，已连接时断开后重新连接
+func (s *Service) connectEndpoints(endpoints []ccsync.Endpoint) error {
+	if s.client.IsActive() {
+		return s.clientStarted(s.client.ReconnectEndpoints(endpoints))
+	}
+	return s.clientStarted(s.client.ConnectEndpoints(endpoints))
+}
+
+// clientStarted 客户端启动成功时通知界面
+func (s *Service) clientStarted(err error) error {
+	if err == nil {
+		s.emitter.Emit("client:active", true)
+	}
+	return err
+}
+
+// SetActiveProfile 切换当前使用的配置方案，已连接时立即切换
+func (s *Service) SetActiveProfile(name string) error {
+	return s.UpdateConfig(func(cfg *config.Config) {
+		cfg.ActiveProfile = name
+	})
+}
+
+func (s *Service) logger() *slog.Logger {
+	return logging.Component("service")
+}
+
+// limits 从配置生成服务端访问控制设置
+func limits(cfg *config.Config) ccsync.Limits {
+	return ccsync.Limits{
+		Allow:         cfg.AllowCIDRs,
+		Deny:          cfg.DenyCIDRs,
+		MaxClients:    cfg.MaxClients,
+		ConnPerMinute: cfg.ConnRateLimit,
+		MsgPerSecond:  cfg.MessageRateLimit,
+	}
+}
+
+// endpoint 将配置方案转换为客户端连接端点
+func endpoint(p config.Profile, deviceID string) ccsync.Endpoint {
+	return ccsync.Endpoint{
+		Name:     p.Name,
+		Address:  p.Address,
+		Secret:   p.Secret,
+		TLSPin:   p.TLSPin,
+		Channel:  p.Channel,
+		DeviceID: deviceID,
+	}
+}
+
+// StartMesh 启动 mesh 模式：运行服务端并连接发现的其他节点
+func (s *Service) StartMesh() error {
+	cfg := s.Config()
+	if err := s.StartServer(cfg.ServerPort); err != nil {
+		return err
+	}
+	if err := s.mesh.Start(cfg.ServerPort, cfg.DiscoveryPort, cfg.ServerSecret, cfg.MeshPeers); err != nil {
+		s.StopServer()
+		return err
+	}
+	s.emitter.Emit("mesh:running", true)
+	return nil
+}
+
+// StopMesh 停止 mesh 模式
+func (s *Service) StopMesh() {
+	s.mesh.Stop()
+	s.StopServer()
+	s.emitter.Emit("mesh:running", false)
+}
+
+// Disconnect 断开连接
+func (s *Service) Disconnect() {
+	s.client.Disconnect()
+	s.connLost.Store(false)
+	s.emitter.Emit("client:active", false)
+	s.emitter.Emit("status", i18n.M("status.disconnected"))
+}
+
+// Start 启动剪贴板监听，并按配置自动启动服务端或连接
+func (s *Service) Start() {
+	s.clipboard.Start()
+
+	// 监听配置文件的手动修改
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopWatch = cancel
+	go config.Watch(ctx, 2*time.Second, s.reloadConfig)
+
+	cfg := s.Config()
+	if cfg.AutoStart {
+		if cfg.Mode == config.ModeMesh {
+			s.StartMesh()
+		}
+		if cfg.RunsServer() {
+			s.StartServer(cfg.ServerPort)
+		}
+		if cfg.RunsClient() {
+			s.ConnectConfigured()
+		}
+	}
+}
+
+// ConnectConfigured 按配置连接服务端：选择了配置方案时使用该方案，否则使用 serverAddress；
+// 两者都未设置时按优先级自动选择方案
+func (s *Service) ConnectConfigured() error {
+	cfg := s.Config()
+	if cfg.ActiveProfile != "" || cfg.ServerAddress == "" {
+		return s.ConnectProfile(cfg.ActiveProfile)
+	}
+	return s.Connect(cfg.ServerAddress)
+}
+
+// ToggleServer 启动或停止服务端，mesh 模式下同时启动或停止 mesh
+func (s *Service) ToggleServer() error {
+	cfg := s.Config()
+	running := s.server.IsRunning()
+	switch {
+	case cfg.Mode == config.ModeMesh && running:
+		s.StopMesh()
+	case cfg.Mode == config.ModeMesh:
+		return s.StartMesh()
+	case running:
+		s.StopServer()
+	default:
+		return s.StartServer(cfg.ServerPort)
+	}
+	return nil
+}
+
+// Shutdown 清理资源
+func (s *Service) Shutdown() {
+	if s.stopWatch != nil {
+		s.stopWatch()
+	}
+
+	s.pauseLock.Lock()
+	if s.resumeTimer != nil {
+		s.resumeTimer.Stop()
+		s.resumeTimer = nil
+	}
+	s.pauseLock.Unlock()
+
+	s.clipboard.Stop()
+	s.mesh.Stop()
+	s.server.Stop()
+	s.client.Disconnect()
+}
+
+func (s *Service) initCallbacks() {
+	// 剪贴板变化 -> 发送给网络
+	s.clipboard.SetHandlers(clipboard.MonitorHandlers{
+		OnChange: s.handleLocal,
+		OnConcealed: func() {
+			s.emitter.Emit("clipboard:concealed")
+		},
+	})
+
+	// 收到消息 -> 更新本地剪贴板
+	s.mesh.SetHandlers(ccsync.MeshHandlers{
+		OnClipboardReceived: func(msg *ccsync.Message, peer *ccsync.Client) {
+			s.handleRemote(msg, fromMesh, peer)
+		},
+		OnPeersChanged: func(count int) {
+			s.emitter.Emit("mesh:peer_count", count)
+		},
+		OnPeerFound: func(id, addr string) {
+			s.notifyPeer(id, id, addr)
+		},
+	})
+
+	s.server.SetHandlers(ccsync.ServerHandlers{
+		OnClipboardReceived: func(msg *ccsync.Message) {
+			s.handleRemote(msg, fromServer, nil)
+		},
+		OnClientConnected: func(count int) {
+			s.emitter.Emit("server:client_count", count)
+		},
+		OnPeerConnected: func(p ccsync.PeerInfo) {
+			s.notifyPeer(p.DeviceID, p.DeviceName, p.Addr)
+		},
+		OnClientDisconnected: func(count int) {
+			s.emitter.Emit("server:client_count", count)
+		},
+		OnPairRequest: func(req ccsync.PairRequest) {
+			s.emitter.Emit("pair:request", req)
+		},
+		OnPaired: s.savePairedDevice,
+		OnMeshPeer: func(id, addr string) {
+			s.mesh.Announce(id, addr)
+		},
+		OnError: func(err error) {
+			s.setServerError(err)
+			s.emitter.Emit("server:error", err.Error())
+			s.emitter.Emit("status", i18n.M("status.serverFailed", "err", err))
+			s.emitter.Emit("server:running", false)
+			s.notifyServerError(err)
+		},
+	})
+
+	s.client.SetHandlers(ccsync.ClientHandlers{
+		OnClipboardReceived: func(msg *ccsync.Message) {
+			s.handleRemote(msg, fromClient, nil)
+		},
+		OnConnected: func(ep ccsync.Endpoint) {
+			s.emitter.Emit("client:status", true)
+			s.emitter.Emit("client:profile", ep.Name)
+			s.notifyConnection(true)
+		},
+		OnDisconnected: func() {
+			s.emitter.Emit("client:status", false)
+			s.notifyConnection(false)
+		},
+	})
+}
+
+// handleLocal 将本地剪贴板变化发送给网络
+func (s *Service) handleLocal(content string, concealed bool) {
+	cfg := s.Config()
+	s.emitter.Emit("clipboard:local", content)
+	if !concealed && !s.isSensitive(content) {
+		s.addRecent(Clip{Content: content})
+	}
+
+	s.logger().Info("service.localCopy", logging.Content(content))
+	if ok, reason := s.canSend(&cfg); !ok {
+		s.logger().Info(reason)
+		return
+	}
+	s.send(&cfg, content, concealed)
+}
+
+// send 将内容发送给所有连接，返回是否有可发送的连接
+func (s *Service) send(cfg *config.Config, content string, concealed bool) bool {
+	msg := ccsync.NewClipboardMessage(content, cfg.Mode)
+	msg.Origin = cfg.DeviceID
+	msg.Device = cfg.DeviceName
+	s.seen.Add(msg.ID)
+	msg.Sensitive = concealed || s.isSensitive(content)
+
+	sent := false
+	if cfg.RunsServer() && s.server.IsRunning() {
+		s.server.Broadcast(msg)
+		sent = true
+	}
+	if cfg.RunsClient() && s.client.IsConnected() {
+		s.client.SendMessage(msg)
+		sent = true
+	}
+	if cfg.Mode == config.ModeMesh && s.mesh.IsRunning() {
+		s.mesh.Send(msg, nil)
+		sent = true
+	}
+	return sent
+}
+
+// remoteSource 远程消息的来源
+type remoteSource int
+
+const (
+	fromServer remoteSource = iota // 本机服务端的下游客户端
+	fromClient                     // 本机客户端连接的上游服务端
+	fromMesh                       // mesh 模式下本机主动连接的节点
+)
+
+// handleRemote 将收到的远程内容写入本地剪贴板，hybrid 与 mesh 模式下同时转发给其他连接。
+// peer 为 mesh 模式下消息来自的节点连接。
+func (s *Service) handleRemote(msg *ccsync.Message, from remoteSource, peer *ccsync.Client) {
+	cfg := s.Config()
+
+	// 防止回环：忽略本机发出或已处理过的消息
+	if msg.Origin == cfg.DeviceID || (msg.ID != "" && !s.seen.Add(msg.ID)) {
+		return
+	}
+
+	switch cfg.Mode {
+	case config.ModeHybrid:
+		s.bridge(msg, from)
+	case config.ModeMesh:
+		s.flood(msg, from, peer)
+	}
+
+	// 仍然可以通知界面收到了消息，但不写入
+	s.logger().Info("service.received", "source", msg.Source, logging.Content(msg.Content))
+	s.setLatest(msg)
+	if ok, reason := s.canReceive(&cfg); !ok {
+		s.logger().Info(reason)
+		return
+	}
+	if needsConfirm(&cfg, msg) {
+		s.addPending(&cfg, msg)
+		return
+	}
+	s.apply(&cfg, msg)
+	s.notifyClip(&cfg, msg)
+}
+
+// apply 将远程内容写入本地剪贴板，并按配置设置自动清除
+func (s *Service) apply(cfg *config.Config, msg *ccsync.Message) {
+	sensitive := msg.Sensitive || s.isSensitive(msg.Content)
+	if ttl := clearAfter(cfg, sensitive); ttl > 0 {
+		s.clipboard.SetContentWithExpiry(msg.Content, ttl, cfg.RestoreAfterClear)
+		s.logger().Info("service.autoClear", "after", ttl)
+	} else {
+		s.clipboard.SetContent(msg.Content)
+	}
+	s.emitter.Emit("clipboard:remote", msg.Content)
+	if !sensitive {
+		source := msg.Device
+		if source == "" {
+			source = msg.Source
+		}
+		s.addRecent(Clip{Content: msg.Content, Source: source, Remote: true})
+	}
+}
+
+// bridge 将一侧收到的消息原样转发到另一侧，保留消息 ID 以便各节点去重
+func (s *Service) bridge(msg *ccsync.Message, from remoteSource) {
+	switch from {
+	case fromServer:
+		if s.client.IsConnected() {
+			s.client.SendMessage(msg)
+		}
+	case fromClient:
+		if s.server.IsRunning() {
+			s.server.Broadcast(msg)
+		}
+	}
+}
+
+// flood 将消息转发给 mesh 中除来源外的所有连接。
+// 服务端已自行转发给其他下游客户端，这里只需补充另一侧。
+func (s *Service) flood(msg *ccsync.Message, from remoteSource, peer *ccsync.Client) {
+	switch from {
+	case fromServer:
+		s.mesh.Send(msg, nil)
+	case fromMesh:
+		s.server.Broadcast(msg)
+		s.mesh.Send(msg, peer)
+	}
+}
+
+// clearAfter 计算远程内容的自动清除时间，0 表示不清除
+func clearAfter(cfg *config.Config, sensitive bool) time.Duration {
+	seconds := cfg.ClearAfter
+	if cfg.ClearSensitiveAfter > 0 && sensitive {
+		if seconds == 0 || cfg.ClearSensitiveAfter < seconds {
+			seconds = cfg.ClearSensitiveAfter
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// isSensitive 检查内容是否匹配任一敏感内容规则
+func (s *Service) isSensitive(content string) bool {
+	s.cfgLock.RLock()
+	patterns := s.sensitive
+	s.cfgLock.RUnlock()
+	for _, re := range patterns {
+		if re.MatchString(content) {
+			return true
+		}
+	}
+	return false
+}
+
+// compilePatterns 编译敏感内容规则。不合法的规则由 config.Validate 报告，这里跳过。
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	var res []*regexp.Regexp
+	for _, p := range patterns {
+		if re, err := regexp.Compile(p); err == nil {
+			res = append(res, re)
+		}
+	}
+	return res
+}
@@ -0,0 +1,456 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"ccsync-net/clipboard"
+	"ccsync-net/config"
+	ccsync "ccsync-net/sync"
+)
+
+type fakeServer struct {
+	running   bool
+	broadcast []*ccsync.Message
+	limitsErr error // SetLimits 返回的错误
+}
+
+func (f *fakeServer) Start(port int) error                         { f.running = true; return nil }
+func (f *fakeServer) Stop() error                                  { f.running = false; return nil }
+func (f *fakeServer) IsRunning() bool                              { return f.running }
+func (f *fakeServer) GetClientCount() int                          { return 0 }
+func (f *fakeServer) Broadcast(msg *ccsync.Message)                { f.broadcast = append(f.broadcast, msg) }
+func (f *fakeServer) SetSecret(secret string)                      {}
+func (f *fakeServer) SetDeviceID(id string)                        {}
+func (f *fakeServer) SetAPIToken(token string)                     {}
+func (f *fakeServer) SetRequirePairing(require bool)               {}
+func (f *fakeServer) SetBindAddresses(entries []string)            {}
+func (f *fakeServer) SetLimits(l ccsync.Limits) error              { return f.limitsErr }
+func (f *fakeServer) SetDevices(devices []ccsync.PairedDevice)     {}
+func (f *fakeServer) SetPairing(enabled bool)                      {}
+func (f *fakeServer) RespondPairing(id string, approve bool) error { return nil }
+func (f *fakeServer) SetHandlers(h ccsync.ServerHandlers)          {}
+
+type fakeClient struct {
+	active    bool
+	connected bool
+	endpoints []ccsync.Endpoint // 最近一次连接的服务端
+	sent      []*ccsync.Message
+}
+
+func (f *fakeClient) ConnectEndpoints(endpoints []ccsync.Endpoint) error {
+	f.active = true
+	f.endpoints = endpoints
+	return nil
+}
+func (f *fakeClient) ReconnectEndpoints(endpoints []ccsync.Endpoint) error {
+	f.endpoints = endpoints
+	return nil
+}
+func (f *fakeClient) Disconnect()               { f.active, f.connected = false, false }
+func (f *fakeClient) IsActive() bool            { return f.active }
+func (f *fakeClient) IsConnected() bool         { return f.connected }
+func (f *fakeClient) Endpoint() ccsync.Endpoint { return ccsync.Endpoint{} }
+func (f *fakeClient) SendMessage(msg *ccsync.Message) error {
+	f.sent = append(f.sent, msg)
+	return nil
+}
+func (f *fakeClient) SetHandlers(h ccsync.ClientHandlers) {}
+
+type fakeMesh struct {
+	running bool
+	sent    []*ccsync.Message
+}
+
+func (f *fakeMesh) Start(port, discoveryPort int, secret string, static []string) error {
+	f.running = true
+	return nil
+}
+func (f *fakeMesh) Stop()                                           { f.running = false }
+func (f *fakeMesh) IsRunning() bool                                 { return f.running }
+func (f *fakeMesh) ConnectedCount() int                             { return 0 }
+func (f *fakeMesh) Send(msg *ccsync.Message, except *ccsync.Client) { f.sent = append(f.sent, msg) }
+func (f *fakeMesh) Announce(id, addr string)                        {}
+func (f *fakeMesh) SetHandlers(h ccsync.MeshHandlers)               {}
+
+type fakeClipboard struct {
+	content   string
+	concealed bool
+	ttl       time.Duration
+}
+
+func (f *fakeClipboard) Start() error              { return nil }
+func (f *fakeClipboard) Stop()                     {}
+func (f *fakeClipboard) GetContent() string        { return f.content }
+func (f *fakeClipboard) IsConcealed() bool         { return f.concealed }
+func (f *fakeClipboard) SetContent(content string) { f.content, f.ttl = content, 0 }
+func (f *fakeClipboard) SetContentWithExpiry(content string, ttl time.Duration, restore bool) {
+	f.content, f.ttl = content, ttl
+}
+func (f *fakeClipboard) SetSyncConcealed(v bool)                 {}
+func (f *fakeClipboard) SetHandlers(h clipboard.MonitorHandlers) {}
+
+type recordEmitter struct {
+	events []string
+}
+
+func (e *recordEmitter) Emit(event string, data ...interface{}) {
+	e.events = append(e.events, event)
+}
+
+type testService struct {
+	*Service
+	server    *fakeServer
+	client    *fakeClient
+	mesh      *fakeMesh
+	clipboard *fakeClipboard
+	emitter   *recordEmitter
+}
+
+// newTestService 使用假的网络与剪贴板创建服务，配置文件写入临时目录
+func newTestService(t *testing.T, update func(cfg *config.Config)) *testService {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	cfg := config.DefaultConfig()
+	if update != nil {
+		update(cfg)
+	}
+	ts := &testService{
+		server:    &fakeServer{},
+		client:    &fakeClient{},
+		mesh:      &fakeMesh{},
+		clipboard: &fakeClipboard{},
+		emitter:   &recordEmitter{},
+	}
+	ts.Service = New(cfg, ts.server, ts.client, ts.mesh, ts.clipboard, ts.emitter)
+	return ts
+}
+
+func TestCanSendReceive(t *testing.T) {
+	tests := []struct {
+		syncMode     string
+		pauseSend    bool
+		pauseReceive bool
+		send         bool
+		receive      bool
+	}{
+		{syncMode: config.SyncBidirectional, send: true, receive: true},
+		{syncMode: config.SyncSendOnly, send: true, receive: false},
+		{syncMode: config.SyncReceiveOnly, send: false, receive: true},
+		{syncMode: config.SyncDisabled, send: false, receive: false},
+		{syncMode: config.SyncManual, send: false, receive: false},
+		{syncMode: config.SyncBidirectional, pauseSend: true, send: false, receive: true},
+		{syncMode: config.SyncBidirectional, pauseReceive: true, send: true, receive: false},
+		{syncMode: config.SyncSendOnly, pauseSend: true, send: false, receive: false},
+	}
+	for _, tt := range tests {
+		ts := newTestService(t, func(cfg *config.Config) { cfg.SyncMode = tt.syncMode })
+		ts.SetPaused(tt.pauseSend, tt.pauseReceive, 0)
+		cfg := ts.Config()
+
+		if ok, reason := ts.canSend(&cfg); ok != tt.send || ok == (reason != "") {
+			t.Errorf("%s pause=%v/%v: canSend = %v %q, want %v", tt.syncMode, tt.pauseSend, tt.pauseReceive, ok, reason, tt.send)
+		}
+		if ok, reason := ts.canReceive(&cfg); ok != tt.receive || ok == (reason != "") {
+			t.Errorf("%s pause=%v/%v: canReceive = %v %q, want %v", tt.syncMode, tt.pauseSend, tt.pauseReceive, ok, reason, tt.receive)
+		}
+	}
+}
+
+func TestHandleLocal(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		syncMode  string
+		server    bool
+		client    bool
+		mesh      bool
+		concealed bool
+		content   string
+		broadcast int
+		clientMsg int
+		meshMsg   int
+		sensitive bool
+	}{
+		{name: "server", mode: config.ModeServer, server: true, content: "a", broadcast: 1},
+		{name: "server stopped", mode: config.ModeServer, content: "a"},
+		{name: "client", mode: config.ModeClient, client: true, content: "a", clientMsg: 1},
+		{name: "client ignores server", mode: config.ModeClient, server: true, client: true, content: "a", clientMsg: 1},
+		{name: "hybrid", mode: config.ModeHybrid, server: true, client: true, content: "a", broadcast: 1, clientMsg: 1},
+		{name: "mesh", mode: config.ModeMesh, server: true, mesh: true, content: "a", meshMsg: 1},
+		{name: "receive only", mode: config.ModeServer, syncMode: config.SyncReceiveOnly, server: true, content: "a"},
+		{name: "manual", mode: config.ModeServer, syncMode: config.SyncManual, server: true, content: "a"},
+		{name: "concealed", mode: config.ModeServer, server: true, concealed: true, content: "a", broadcast: 1, sensitive: true},
+		{name: "sensitive pattern", mode: config.ModeServer, server: true, content: "token-123", broadcast: 1, sensitive: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, func(cfg *config.Config) {
+				cfg.Mode = tt.mode
+				if tt.syncMode != "" {
+					cfg.SyncMode = tt.syncMode
+				}
+				cfg.SensitivePatterns = []string{`^token-\d+$`}
+			})
+			ts.server.running = tt.server
+			ts.client.active, ts.client.connected = tt.client, tt.client
+			ts.mesh.running = tt.mesh
+
+			ts.handleLocal(tt.content, tt.concealed)
+
+			if len(ts.server.broadcast) != tt.broadcast || len(ts.client.sent) != tt.clientMsg || len(ts.mesh.sent) != tt.meshMsg {
+				t.Fatalf("sent server=%d client=%d mesh=%d, want %d %d %d",
+					len(ts.server.broadcast), len(ts.client.sent), len(ts.mesh.sent), tt.broadcast, tt.clientMsg, tt.meshMsg)
+			}
+			for _, msgs := range [][]*ccsync.Message{ts.server.broadcast, ts.client.sent, ts.mesh.sent} {
+				for _, msg := range msgs {
+					cfg := ts.Config()
+					if msg.Content != tt.content || msg.Origin != cfg.DeviceID || msg.Sensitive != tt.sensitive {
+						t.Errorf("message = %+v, want content %q origin %q sensitive %v", msg, tt.content, cfg.DeviceID, tt.sensitive)
+					}
+				}
+			}
+			if recent := ts.RecentClips(); (len(recent) == 1) == tt.sensitive {
+				t.Errorf("recent clips = %d, sensitive %v", len(recent), tt.sensitive)
+			}
+		})
+	}
+}
+
+func TestHandleRemote(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		syncMode  string
+		from      remoteSource
+		msg       ccsync.Message
+		applied   bool
+		ttl       time.Duration
+		broadcast int
+		clientMsg int
+		meshMsg   int
+	}{
+		{name: "server", mode: config.ModeServer, from: fromServer, msg: ccsync.Message{ID: "1", Content: "a"}, applied: true},
+		{name: "client", mode: config.ModeClient, from: fromClient, msg: ccsync.Message{ID: "1", Content: "a"}, applied: true},
+		{name: "send only", mode: config.ModeServer, syncMode: config.SyncSendOnly, from: fromServer, msg: ccsync.Message{ID: "1", Content: "a"}},
+		{name: "manual", mode: config.ModeServer, syncMode: config.SyncManual, from: fromServer, msg: ccsync.Message{ID: "1", Content: "a"}},
+		{name: "sensitive", mode: config.ModeServer, from: fromServer, msg: ccsync.Message{ID: "1", Content: "a", Sensitive: true}, applied: true, ttl: 30 * time.Second},
+		{name: "hybrid from server", mode: config.ModeHybrid, from: fromServer, msg: ccsync.Message{ID: "1", Content: "a"}, applied: true, clientMsg: 1},
+		{name: "hybrid from client", mode: config.ModeHybrid, from: fromClient, msg: ccsync.Message{ID: "1", Content: "a"}, applied: true, broadcast: 1},
+		{name: "mesh from server", mode: config.ModeMesh, from: fromServer, msg: ccsync.Message{ID: "1", Content: "a"}, applied: true, meshMsg: 1},
+		{name: "mesh from peer", mode: config.ModeMesh, from: fromMesh, msg: ccsync.Message{ID: "1", Content: "a"}, applied: true, broadcast: 1, meshMsg: 1},
+		{name: "forward while receive disabled", mode: config.ModeHybrid, syncMode: config.SyncSendOnly, from: fromClient, msg: ccsync.Message{ID: "1", Content: "a"}, broadcast: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, func(cfg *config.Config) {
+				cfg.Mode = tt.mode
+				if tt.syncMode != "" {
+					cfg.SyncMode = tt.syncMode
+				}
+			})
+			ts.server.running = true
+			ts.client.active, ts.client.connected = true, true
+			ts.mesh.running = true
+
+			msg := tt.msg
+			ts.handleRemote(&msg, tt.from, nil)
+
+			if applied := ts.clipboard.content == tt.msg.Content; applied != tt.applied {
+				t.Errorf("applied = %v, want %v", applied, tt.applied)
+			}
+			if ts.clipboard.ttl != tt.ttl {
+				t.Errorf("ttl = %v, want %v", ts.clipboard.ttl, tt.ttl)
+			}
+			if len(ts.server.broadcast) != tt.broadcast || len(ts.client.sent) != tt.clientMsg || len(ts.mesh.sent) != tt.meshMsg {
+				t.Errorf("forwarded server=%d client=%d mesh=%d, want %d %d %d",
+					len(ts.server.broadcast), len(ts.client.sent), len(ts.mesh.sent), tt.broadcast, tt.clientMsg, tt.meshMsg)
+			}
+		})
+	}
+}
+
+func TestConnectConfigured(t *testing.T) {
+	work := config.Profile{Name: "work", Address: "10.0.0.2:8765", Secret: "s"}
+	home := config.Profile{Name: "home", Address: "10.0.0.3:8765"}
+	tests := []struct {
+		name     string
+		address  string
+		profiles []config.Profile
+		active   string
+		want     []string // 连接的服务端地址
+		secret   string   // 第一个服务端使用的密钥
+	}{
+		{name: "address only", address: "10.0.0.1:8765", want: []string{"10.0.0.1:8765"}},
+		{name: "no active profile uses address", address: "10.0.0.1:8765", profiles: []config.Profile{work}, want: []string{"10.0.0.1:8765"}},
+		{name: "address matches profile", address: work.Address, profiles: []config.Profile{work}, want: []string{work.Address}, secret: work.Secret},
+		{name: "active profile", address: "10.0.0.1:8765", profiles: []config.Profile{work, home}, active: "home", want: []string{home.Address}},
+		{name: "no address selects profiles", profiles: []config.Profile{work, home}, want: []string{work.Address, home.Address}, secret: work.Secret},
+	}
+	for _, tt := range tests {
+		ts := newTestService(t, func(cfg *config.Config) {
+			cfg.Mode = config.ModeClient
+			cfg.ServerAddress = tt.address
+			cfg.Profiles = tt.profiles
+			cfg.ActiveProfile = tt.active
+		})
+		ts.ConnectConfigured()
+
+		var got []string
+		for _, ep := range ts.client.endpoints {
+			got = append(got, ep.Address)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: endpoints = %v, want %v", tt.name, got, tt.want)
+		}
+		if len(ts.client.endpoints) > 0 && ts.client.endpoints[0].Secret != tt.secret {
+			t.Errorf("%s: secret = %q, want %q", tt.name, ts.client.endpoints[0].Secret, tt.secret)
+		}
+	}
+}
+
+func TestAddressChangeReconnects(t *testing.T) {
+	ts := newTestService(t, func(cfg *config.Config) {
+		cfg.Mode = config.ModeClient
+		cfg.Profiles = []config.Profile{{Name: "work", Address: "10.0.0.2:8765"}}
+	})
+	ts.ConnectConfigured()
+
+	if err := ts.UpdateConfig(func(cfg *config.Config) { cfg.ServerAddress = "10.0.0.9:8765" }); err != nil {
+		t.Fatal(err)
+	}
+	if len(ts.client.endpoints) != 1 || ts.client.endpoints[0].Address != "10.0.0.9:8765" {
+		t.Errorf("endpoints = %+v, want the new address", ts.client.endpoints)
+	}
+}
+
+func TestApplyChangesLimitsError(t *testing.T) {
+	ts := newTestService(t, nil)
+	ts.server.limitsErr = errors.New("bad limits")
+
+	if err := ts.UpdateConfig(func(cfg *config.Config) { cfg.MaxClients = 5 }); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(ts.emitter.events, "config:error") {
+		t.Errorf("events = %v, want config:error", ts.emitter.events)
+	}
+}
+
+func TestApplyChangesMode(t *testing.T) {
+	tests := []struct {
+		from, to string
+		server   bool
+		client   bool
+		mesh     bool
+	}{
+		{from: config.ModeHybrid, to: config.ModeServer, server: true},
+		{from: config.ModeHybrid, to: config.ModeClient, client: true},
+		{from: config.ModeHybrid, to: config.ModeHybrid, server: true, client: true},
+		{from: config.ModeServer, to: config.ModeClient, client: true},
+		{from: config.ModeClient, to: config.ModeServer, server: true},
+		{from: config.ModeMesh, to: config.ModeServer},
+		{from: config.ModeMesh, to: config.ModeClient, client: true},
+	}
+	for _, tt := range tests {
+		ts := newTestService(t, func(cfg *config.Config) { cfg.Mode = tt.from })
+		ts.server.running = true
+		ts.client.active = true
+		ts.mesh.running = tt.from == config.ModeMesh
+
+		if err := ts.UpdateConfig(func(cfg *config.Config) { cfg.Mode = tt.to }); err != nil {
+			t.Fatalf("%s -> %s: %v", tt.from, tt.to, err)
+		}
+		if ts.server.running != tt.server || ts.client.active != tt.client || ts.mesh.running != tt.mesh {
+			t.Errorf("%s -> %s: server=%v client=%v mesh=%v, want %v %v %v", tt.from, tt.to,
+				ts.server.running, ts.client.active, ts.mesh.running, tt.server, tt.client, tt.mesh)
+		}
+	}
+}
+
+func TestHandleRemoteLoop(t *testing.T) {
+	ts := newTestService(t, func(cfg *config.Config) { cfg.Mode = config.ModeHybrid })
+	ts.server.running = true
+	ts.client.active, ts.client.connected = true, true
+	cfg := ts.Config()
+
+	// 本机发出的内容被对端转发回来
+	ts.handleLocal("local", false)
+	echo := *ts.client.sent[0]
+	ts.handleRemote(&echo, fromClient, nil)
+
+	// 其他设备的内容，ID 已处理过的重复消息
+	msg := ccsync.Message{ID: "remote-1", Origin: "other", Content: "remote"}
+	dup := msg
+	ts.handleRemote(&msg, fromClient, nil)
+	ts.clipboard.content = ""
+	ts.handleRemote(&dup, fromServer, nil)
+	if ts.clipboard.content != "" {
+		t.Errorf("duplicate message applied")
+	}
+
+	// 同一设备的新消息仍然接受
+	next := ccsync.Message{ID: "remote-2", Origin: "other", Content: "remote"}
+	ts.handleRemote(&next, fromClient, nil)
+
+	// 伪造本机 ID 的消息，即使 ID 未出现过也丢弃
+	forged := ccsync.Message{ID: "remote-3", Origin: cfg.DeviceID, Content: "forged"}
+	ts.handleRemote(&forged, fromClient, nil)
+
+	if ts.clipboard.content != "remote" {
+		t.Errorf("clipboard = %q, want %q", ts.clipboard.content, "remote")
+	}
+	// 本机内容发送一次，remote-1 与 remote-2 各转发一次
+	if len(ts.server.broadcast) != 3 || len(ts.client.sent) != 1 {
+		t.Errorf("server=%d client=%d, want 3 1", len(ts.server.broadcast), len(ts.client.sent))
+	}
+}
+
+func TestPushClipboard(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		concealed     bool
+		syncConcealed bool
+		sent          bool
+		sensitive     bool
+	}{
+		{name: "plain", content: "a", sent: true},
+		{name: "empty", content: " "},
+		{name: "sensitive pattern", content: "token-1", sent: true, sensitive: true},
+		{name: "concealed", content: "a", concealed: true},
+		{name: "concealed allowed", content: "a", concealed: true, syncConcealed: true, sent: true, sensitive: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, func(cfg *config.Config) {
+				cfg.SyncMode = config.SyncManual
+				cfg.SyncConcealed = tt.syncConcealed
+				cfg.SensitivePatterns = []string{`^token-\d+$`}
+			})
+			ts.server.running = true
+			ts.clipboard.content, ts.clipboard.concealed = tt.content, tt.concealed
+
+			err := ts.PushClipboard()
+			if (err == nil) != tt.sent || len(ts.server.broadcast) != btoi(tt.sent) {
+				t.Fatalf("PushClipboard = %v, broadcast %d, want sent %v", err, len(ts.server.broadcast), tt.sent)
+			}
+			if tt.sent && ts.server.broadcast[0].Sensitive != tt.sensitive {
+				t.Errorf("Sensitive = %v, want %v", ts.server.broadcast[0].Sensitive, tt.sensitive)
+			}
+			if recent := ts.RecentClips(); len(recent) != btoi(tt.sent && !tt.sensitive) {
+				t.Errorf("recent clips = %d", len(recent))
+			}
+		})
+	}
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
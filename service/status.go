@@ -0,0 +1,54 @@
+package service
+
+// Status 服务运行状态
+type Status struct {
+	ServerRunning   bool   `json:"serverRunning"`
+	ServerPort      int    `json:"serverPort"`
+	Clients         int    `json:"clients"` // 连接到本机服务端的客户端数
+	ServerError     string `json:"serverError,omitempty"`
+	ClientActive    bool   `json:"clientActive"` // 客户端已启动，包括正在重连
+	ClientConnected bool   `json:"clientConnected"`
+	ClientEndpoint  string `json:"clientEndpoint,omitempty"` // 正在使用的配置方案名称或地址
+	MeshRunning     bool   `json:"meshRunning"`
+	MeshPeers       int    `json:"meshPeers"`
+}
+
+// Status 返回当前运行状态
+func (s *Service) Status() Status {
+	cfg := s.Config()
+	st := Status{
+		ServerRunning:   s.server.IsRunning(),
+		ServerPort:      cfg.ServerPort,
+		Clients:         s.server.GetClientCount(),
+		ClientActive:    s.client.IsActive(),
+		ClientConnected: s.client.IsConnected(),
+		MeshRunning:     s.mesh.IsRunning(),
+		MeshPeers:       s.mesh.ConnectedCount(),
+	}
+	if st.ClientActive {
+		ep := s.client.Endpoint()
+		st.ClientEndpoint = ep.Name
+		if st.ClientEndpoint == "" {
+			st.ClientEndpoint = ep.Address
+		}
+	}
+
+	s.statusLock.Lock()
+	if s.serverErr != nil {
+		st.ServerError = s.serverErr.Error()
+	}
+	s.statusLock.Unlock()
+	return st
+}
+
+// HasError 是否处于错误状态：服务端异常停止或启动失败，或客户端连接断开正在重连
+func (st Status) HasError() bool {
+	return st.ServerError != "" || (st.ClientActive && !st.ClientConnected)
+}
+
+// setServerError 记录服务端错误，nil 表示已恢复
+func (s *Service) setServerError(err error) {
+	s.statusLock.Lock()
+	s.serverErr = err
+	s.statusLock.Unlock()
+}
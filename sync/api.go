@@ -0,0 +1,253 @@
+package sync
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"ccsync-net/i18n"
+	"ccsync-net/logging"
+)
+
+// maxAPIBody POST /api/clip 请求体的最大长度
+const maxAPIBody = 10 << 20
+
+// PeerInfo 已连接客户端的信息
+type PeerInfo struct {
+	Addr        string `json:"addr"`
+	Channel     string `json:"channel"`
+	DeviceID    string `json:"deviceId,omitempty"`
+	DeviceName  string `json:"deviceName,omitempty"`
+	ConnectedAt int64  `json:"connectedAt"`
+}
+
+// Status 服务端运行状态
+type Status struct {
+	Running   bool        `json:"running"`
+	Addresses []string    `json:"addresses"`
+	StartedAt int64       `json:"startedAt,omitempty"`
+	Clients   int         `json:"clients"`
+	Pairing   bool        `json:"pairing"`
+	Rejects   RejectStats `json:"rejects"`
+}
+
+// apiClipRequest POST /api/clip 的 JSON 请求体
+type apiClipRequest struct {
+	Content   string `json:"content"`
+	Channel   string `json:"channel"`
+	Source    string `json:"source"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+// SetAPIToken 设置 REST API 的访问令牌，为空时不启用 API
+func (s *Server) SetAPIToken(token string) {
+	s.runningLock.Lock()
+	s.apiToken = token
+	s.runningLock.Unlock()
+}
+
+func (s *Server) apiEnabled() bool {
+	s.runningLock.RLock()
+	defer s.runningLock.RUnlock()
+	return s.apiToken != ""
+}
+
+// apiAuthorized 校验请求携带的 API 令牌
+func (s *Server) apiAuthorized(r *http.Request) bool {
+	s.runningLock.RLock()
+	token := s.apiToken
+	s.runningLock.RUnlock()
+
+	given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// permitted 检查请求来源是否在允许的地址范围内
+func (s *Server) permitted(r *http.Request) bool {
+	s.runningLock.RLock()
+	policy := s.policy
+	s.runningLock.RUnlock()
+
+	ip := net.ParseIP(remoteHost(r))
+	return ip != nil && policy.permits(ip)
+}
+
+// remoteHost 返回请求来源的 IP 地址部分
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Peers 返回已连接的客户端列表
+func (s *Server) Peers() []PeerInfo {
+	s.clientsLock.RLock()
+	clients := make([]*peer, 0, len(s.clients))
+	for _, p := range s.clients {
+		clients = append(clients, p)
+	}
+	s.clientsLock.RUnlock()
+
+	peers := make([]PeerInfo, 0, len(clients))
+	for _, p := range clients {
+		peers = append(peers, s.peerInfo(p))
+	}
+	return peers
+}
+
+// peerInfo 返回客户端连接的信息，已配对设备附带设备名称
+func (s *Server) peerInfo(p *peer) PeerInfo {
+	info := PeerInfo{
+		Addr:        p.addr,
+		Channel:     p.channel,
+		DeviceID:    p.deviceID,
+		ConnectedAt: p.connectedAt.UnixMilli(),
+	}
+	s.pairing.lock.Lock()
+	if d, ok := s.pairing.devices[p.deviceID]; ok {
+		info.DeviceName = d.Name
+	}
+	s.pairing.lock.Unlock()
+	return info
+}
+
+// Status 返回服务端运行状态
+func (s *Server) Status() Status {
+	s.runningLock.RLock()
+	st := Status{Running: s.running}
+	if s.running {
+		st.Addresses = append([]string(nil), s.listenAddrs...)
+		st.StartedAt = s.startedAt.UnixMilli()
+	}
+	s.runningLock.RUnlock()
+
+	st.Clients = s.GetClientCount()
+	st.Pairing = s.IsPairing()
+	st.Rejects = s.RejectStats()
+	return st
+}
+
+// handleAPI 为 API 处理函数添加访问控制和令牌认证
+func (s *Server) handleAPI(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.apiEnabled() {
+			writeJSONError(w, http.StatusNotFound, i18n.T("api.disabled"))
+			return
+		}
+		if !s.permitted(r) {
+			s.rejects.denied.Add(1)
+			writeJSONError(w, http.StatusForbidden, i18n.T("reject.denied"))
+			return
+		}
+		if !s.apiAuthorized(r) {
+			s.rejects.unauthorized.Add(1)
+			s.logger().Warn("api.unauthorized", "addr", r.RemoteAddr)
+			writeJSONError(w, http.StatusUnauthorized, i18n.T("reject.unauthorized"))
+			return
+		}
+
+		h(w, r)
+	}
+}
+
+// apiPostClip 处理 POST /api/clip：广播一条剪贴板内容。
+// 请求体为 JSON 时读取 content/channel/source/sensitive 字段，否则整个请求体作为内容，
+// 频道和来源可通过查询参数 channel、source 指定。
+func (s *Server) apiPostClip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, i18n.T("api.methodNotAllowed", "method", http.MethodPost))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAPIBody))
+	if err != nil {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, i18n.T("api.tooLarge"))
+		return
+	}
+
+	req := apiClipRequest{
+		Channel: r.URL.Query().Get("channel"),
+		Source:  r.URL.Query().Get("source"),
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, i18n.T("api.badRequest", "err", err))
+			return
+		}
+	} else {
+		req.Content = string(body)
+	}
+
+	if req.Content == "" {
+		writeJSONError(w, http.StatusBadRequest, i18n.T("api.emptyContent"))
+		return
+	}
+	if req.Source == "" {
+		req.Source = "api"
+	}
+
+	msg := NewClipboardMessage(req.Content, req.Source)
+	msg.Sensitive = req.Sensitive
+	data, err := json.Marshal(msg)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.metrics.received.Add(1)
+	s.publish(req.Channel, msg, data, nil)
+	s.logger().Info("api.clipPosted", "source", req.Source, "channel", req.Channel, logging.Content(req.Content))
+	writeJSON(w, http.StatusOK, map[string]string{"id": msg.ID})
+}
+
+// apiLatestClip 处理 GET /api/clip/latest?channel=...
+func (s *Server) apiLatestClip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, i18n.T("api.methodNotAllowed", "method", http.MethodGet))
+		return
+	}
+
+	last := s.LastClip(r.URL.Query().Get("channel"))
+	if last == nil {
+		writeJSONError(w, http.StatusNotFound, i18n.T("api.noContent"))
+		return
+	}
+	writeJSON(w, http.StatusOK, last)
+}
+
+// apiPeers 处理 GET /api/peers
+func (s *Server) apiPeers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, i18n.T("api.methodNotAllowed", "method", http.MethodGet))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Peers())
+}
+
+// apiStatus 处理 GET /api/status
+func (s *Server) apiStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, i18n.T("api.methodNotAllowed", "method", http.MethodGet))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Status())
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
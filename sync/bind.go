@@ -0,0 +1,101 @@
+package sync
+
+import (
+	"net"
+	"strconv"
+
+	"ccsync-net/i18n"
+)
+
+// LocalAddress 本机网络地址
+type LocalAddress struct {
+	Interface string `json:"interface"` // 网卡名称
+	IP        string `json:"ip"`
+	Loopback  bool   `json:"loopback"`
+	IPv6      bool   `json:"ipv6"`
+}
+
+// LocalAddresses 列出本机所有已启用网卡的地址，供客户端连接时参考
+func LocalAddresses() ([]LocalAddress, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []LocalAddress
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		for _, ip := range interfaceIPs(iface) {
+			result = append(result, LocalAddress{
+				Interface: iface.Name,
+				IP:        ip.String(),
+				Loopback:  ip.IsLoopback(),
+				IPv6:      ip.To4() == nil,
+			})
+		}
+	}
+	return result, nil
+}
+
+// ResolveBindAddresses 将绑定配置解析为监听地址列表。
+// 每一项可以是 IP 地址 (如 "127.0.0.1"、"::1") 或网卡名称 (如 "eth0")；
+// 列表为空时监听所有地址。
+func ResolveBindAddresses(entries []string, port int) ([]string, error) {
+	p := strconv.Itoa(port)
+	if len(entries) == 0 {
+		return []string{net.JoinHostPort("0.0.0.0", p)}, nil
+	}
+
+	var addrs []string
+	seen := make(map[string]bool)
+	add := func(ip string) {
+		addr := net.JoinHostPort(ip, p)
+		if !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+
+	for _, entry := range entries {
+		if ip := net.ParseIP(entry); ip != nil {
+			add(ip.String())
+			continue
+		}
+
+		iface, err := net.InterfaceByName(entry)
+		if err != nil {
+			return nil, i18n.Errorf("bind.unknown", "value", entry)
+		}
+		ips := interfaceIPs(*iface)
+		if len(ips) == 0 {
+			return nil, i18n.Errorf("bind.noAddress", "value", entry)
+		}
+		for _, ip := range ips {
+			add(ip.String())
+		}
+	}
+	return addrs, nil
+}
+
+// interfaceIPs 返回网卡上可用于监听的地址，跳过 IPv6 链路本地地址
+func interfaceIPs(iface net.Interface) []net.IP {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil
+	}
+
+	var ips []net.IP
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ipNet.IP.To4() == nil && ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		ips = append(ips, ipNet.IP)
+	}
+	return ips
+}
@@ -0,0 +1,76 @@
+package sync
+
+import (
+	"net"
+	"slices"
+	"testing"
+)
+
+func TestResolveBindAddresses(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", want: []string{"0.0.0.0:8765"}},
+		{name: "ipv4", entries: []string{"127.0.0.1"}, want: []string{"127.0.0.1:8765"}},
+		{name: "ipv6", entries: []string{"::1"}, want: []string{"[::1]:8765"}},
+		{name: "normalized", entries: []string{"0:0:0:0:0:0:0:1"}, want: []string{"[::1]:8765"}},
+		{name: "duplicates", entries: []string{"127.0.0.1", "::1", "127.0.0.1"}, want: []string{"127.0.0.1:8765", "[::1]:8765"}},
+		{name: "unknown interface", entries: []string{"no-such-if0"}, wantErr: true},
+		{name: "unknown after valid", entries: []string{"127.0.0.1", "no-such-if0"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ResolveBindAddresses(tt.entries, 8765)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v", tt.name, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveBindInterface(t *testing.T) {
+	iface := loopbackInterface(t)
+	ips := interfaceIPs(iface)
+	if len(ips) == 0 {
+		t.Skipf("%s has no usable address", iface.Name)
+	}
+	for _, ip := range ips {
+		if ip.To4() == nil && ip.IsLinkLocalUnicast() {
+			t.Errorf("link-local address %s not skipped", ip)
+		}
+	}
+
+	got, err := ResolveBindAddresses([]string{iface.Name, ips[0].String()}, 8765)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for _, ip := range ips {
+		want = append(want, net.JoinHostPort(ip.String(), "8765"))
+	}
+	// 网卡地址与单独列出的同一地址只监听一次
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// loopbackInterface 返回本机已启用的回环网卡，不存在时跳过测试
+func loopbackInterface(t *testing.T) net.Interface {
+	t.Helper()
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 && iface.Flags&net.FlagUp != 0 {
+			return iface
+		}
+	}
+	t.Skip("no loopback interface")
+	return net.Interface{}
+}
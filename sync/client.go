@@ -2,68 +2,150 @@ package sync
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"sync"
 	"time"
 
+	"ccsync-net/i18n"
+	"ccsync-net/logging"
+
 	"github.com/gorilla/websocket"
 )
 
 // Client WebSocket 客户端
 type Client struct {
-	serverAddr  string
+	endpoints   []Endpoint
+	current     int
 	conn        *websocket.Conn
 	connected   bool
 	connLock    sync.RWMutex
+	writeLock   sync.Mutex
 	stopChan    chan struct{}
 	reconnect   bool
+	generation  int
+	remoteID    string // mesh 连接中对方节点的设备 ID
+	stats       *clientMetrics
+
+	ClientHandlers
+
+	component string // 日志中的组件名称
+}
 
-	// 回调函数
-	OnClipboardReceived func(content string)
-	OnConnected         func()
+// ClientHandlers 客户端的回调函数
+type ClientHandlers struct {
+	OnClipboardReceived func(msg *Message)
+	OnConnected         func(ep Endpoint)
 	OnDisconnected      func()
-	OnLog               func(msg string)
+}
+
+// SetHandlers 设置回调函数，应在连接之前调用
+func (c *Client) SetHandlers(h ClientHandlers) {
+	c.ClientHandlers = h
 }
 
 // NewClient 创建客户端实例
 func NewClient() *Client {
 	return &Client{
-		stopChan: make(chan struct{}),
+		stopChan:  make(chan struct{}),
+		component: "client",
+		stats:     newClientMetrics(),
 	}
 }
 
 // Connect 连接到服务端
 func (c *Client) Connect(serverAddr string) error {
+	return c.ConnectEndpoints([]Endpoint{{Address: serverAddr}})
+}
+
+// ConnectEndpoints 按优先级依次尝试连接服务端，使用第一个可连接的。
+// 断线后重新从优先级最高的开始尝试。
+func (c *Client) ConnectEndpoints(endpoints []Endpoint) error {
+	if len(endpoints) == 0 {
+		return i18n.Errorf("client.noServer")
+	}
+
 	c.connLock.Lock()
-	if c.connected {
+	if c.connected || c.reconnect {
+		// 已连接或正在重连
 		c.connLock.Unlock()
 		return nil
 	}
-	c.serverAddr = serverAddr
+	c.endpoints = append([]Endpoint(nil), endpoints...)
+	c.current = 0
 	c.reconnect = true
+	c.generation++
+	gen := c.generation
 	c.connLock.Unlock()
 
-	go c.connectLoop()
+	registerClient(c)
+	go c.connectLoop(gen)
 	return nil
 }
 
+// Reconnect 断开当前连接并连接到新地址
+func (c *Client) Reconnect(serverAddr string) error {
+	c.Disconnect()
+	return c.Connect(serverAddr)
+}
+
+// ReconnectEndpoints 断开当前连接并按新的服务端列表重新连接
+func (c *Client) ReconnectEndpoints(endpoints []Endpoint) error {
+	c.Disconnect()
+	return c.ConnectEndpoints(endpoints)
+}
+
 // Disconnect 断开连接
 func (c *Client) Disconnect() {
 	c.connLock.Lock()
 	c.reconnect = false
+	c.generation++
 	if c.conn != nil {
 		c.conn.Close()
 		c.conn = nil
 	}
 	c.connected = false
 	c.connLock.Unlock()
+	unregisterClient(c)
 
 	select {
 	case c.stopChan <- struct{}{}:
 	default:
 	}
 
-	c.log("已断开连接")
+	c.logger().Info("client.disconnected")
+}
+
+// metricsLabel 统计中区分客户端的标签：mesh 客户端为 mesh/节点名称，其他为组件名称
+func (c *Client) metricsLabel() string {
+	if c.component == "mesh" {
+		return "mesh/" + c.Endpoint().Name
+	}
+	return c.component
+}
+
+// IsActive 检查是否处于连接或重连状态
+func (c *Client) IsActive() bool {
+	c.connLock.RLock()
+	defer c.connLock.RUnlock()
+	return c.reconnect
+}
+
+// Endpoint 返回当前连接（或最近尝试）的服务端
+func (c *Client) Endpoint() Endpoint {
+	c.connLock.RLock()
+	defer c.connLock.RUnlock()
+	if c.current >= len(c.endpoints) {
+		return Endpoint{}
+	}
+	return c.endpoints[c.current]
+}
+
+// RemoteID 返回 mesh 连接中对方节点已证明的设备 ID，其他连接返回空字符串
+func (c *Client) RemoteID() string {
+	c.connLock.RLock()
+	defer c.connLock.RUnlock()
+	return c.remoteID
 }
 
 // IsConnected 检查是否已连接
@@ -75,6 +157,11 @@ func (c *Client) IsConnected() bool {
 
 // SendClipboard 发送剪贴板内容
 func (c *Client) SendClipboard(content, source string) error {
+	return c.SendMessage(NewClipboardMessage(content, source))
+}
+
+// SendMessage 发送消息
+func (c *Client) SendMessage(msg *Message) error {
 	c.connLock.RLock()
 	conn := c.conn
 	connected := c.connected
@@ -84,51 +171,105 @@ func (c *Client) SendClipboard(content, source string) error {
 		return nil
 	}
 
-	msg := NewClipboardMessage(content, source)
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return err
 	}
 
-	return conn.WriteMessage(websocket.TextMessage, data)
+	if err := c.write(conn, data); err != nil {
+		return err
+	}
+	if msg.Type == TypeClipboard {
+		c.stats.sent.Add(1)
+	}
+	return nil
+}
+
+// write 发送数据，同一连接不允许并发写入
+func (c *Client) write(conn *websocket.Conn, data []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		c.stats.writeErrors.Add(1)
+		return err
+	}
+	c.stats.bytesOut.Add(uint64(len(data)))
+	return nil
 }
 
-func (c *Client) connectLoop() {
+func (c *Client) connectLoop(gen int) {
+	// 曾经连接成功后断开，再次连接成功时计为一次重连
+	lost := false
 	for {
 		c.connLock.RLock()
-		shouldReconnect := c.reconnect
-		serverAddr := c.serverAddr
+		shouldReconnect := c.reconnect && c.generation == gen
+		endpoints := c.endpoints
 		c.connLock.RUnlock()
 
 		if !shouldReconnect {
 			return
 		}
 
-		url := "ws://" + serverAddr + "/ws"
-		c.log("正在连接 " + url)
+		// 按优先级尝试每个服务端
+		var conn *websocket.Conn
+		var index int
+		var remoteID string
+		delay := 3 * time.Second
+		for i, ep := range endpoints {
+			c.logger().Info("client.connecting", "endpoint", ep.String())
+
+			var err error
+			conn, remoteID, err = ep.dial()
+			if err == nil {
+				index = i
+				break
+			}
+			if errors.Is(err, errMeshYield) {
+				// 由对方发起连接，稍后再次通知
+				c.logger().Debug("client.meshYield", "endpoint", ep.String(), "peer", remoteID)
+				delay = meshYieldInterval
+				continue
+			}
+			c.logger().Warn("client.connectFailed", "endpoint", ep.String(), "err", err)
+		}
 
-		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
-		if err != nil {
-			c.log("连接失败: " + err.Error())
-			time.Sleep(3 * time.Second)
+		if conn == nil {
+			time.Sleep(delay)
 			continue
 		}
 
 		c.connLock.Lock()
+		if c.generation != gen {
+			// 连接期间已被断开或切换地址
+			c.connLock.Unlock()
+			conn.Close()
+			return
+		}
 		c.conn = conn
 		c.connected = true
+		c.current = index
+		c.remoteID = remoteID
 		c.connLock.Unlock()
 
-		c.log("连接成功")
+		c.stats.connected.Add(1)
+		if lost {
+			c.stats.reconnects.Add(1)
+		}
+
+		c.logger().Info("client.connected", "endpoint", endpoints[index].String())
 		if c.OnConnected != nil {
-			c.OnConnected()
+			c.OnConnected(endpoints[index])
 		}
 
 		c.readLoop(conn)
+		c.stats.connected.Add(-1)
+		lost = true
 
 		c.connLock.Lock()
-		c.connected = false
-		c.conn = nil
+		if c.generation == gen {
+			c.connected = false
+			c.conn = nil
+		}
 		c.connLock.Unlock()
 
 		if c.OnDisconnected != nil {
@@ -136,11 +277,11 @@ func (c *Client) connectLoop() {
 		}
 
 		c.connLock.RLock()
-		shouldReconnect = c.reconnect
+		shouldReconnect = c.reconnect && c.generation == gen
 		c.connLock.RUnlock()
 
 		if shouldReconnect {
-			c.log("连接断开，3秒后重连...")
+			c.logger().Warn("client.reconnecting")
 			time.Sleep(3 * time.Second)
 		}
 	}
@@ -153,9 +294,10 @@ func (c *Client) readLoop(conn *websocket.Conn) {
 	for {
 		_, data, err := conn.ReadMessage()
 		if err != nil {
-			c.log("读取消息失败: " + err.Error())
+			c.logger().Debug("client.readFailed", "err", err)
 			return
 		}
+		c.stats.bytesIn.Add(uint64(len(data)))
 
 		var msg Message
 		if err := json.Unmarshal(data, &msg); err != nil {
@@ -164,8 +306,12 @@ func (c *Client) readLoop(conn *websocket.Conn) {
 
 		switch msg.Type {
 		case TypeClipboard:
+			c.stats.received.Add(1)
+			c.stats.latency.observeLatency(&msg)
+			// 信任上游服务端认证的发送方
+			msg.AuthDevice = msg.VerifiedDevice
 			if c.OnClipboardReceived != nil {
-				c.OnClipboardReceived(msg.Content)
+				c.OnClipboardReceived(&msg)
 			}
 		case TypePong:
 			// 心跳响应，忽略
@@ -190,7 +336,7 @@ func (c *Client) heartbeat(conn *websocket.Conn) {
 
 			ping := NewPingMessage()
 			data, _ := json.Marshal(ping)
-			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			if err := c.write(conn, data); err != nil {
 				return
 			}
 		case <-c.stopChan:
@@ -199,9 +345,6 @@ func (c *Client) heartbeat(conn *websocket.Conn) {
 	}
 }
 
-func (c *Client) log(msg string) {
-	log.Println("[Client]", msg)
-	if c.OnLog != nil {
-		c.OnLog(msg)
-	}
+func (c *Client) logger() *slog.Logger {
+	return logging.Component(c.component)
 }
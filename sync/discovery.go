@@ -0,0 +1,124 @@
+package sync
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"ccsync-net/logging"
+)
+
+// discoveryApp 广播包中的应用标识，用于过滤其他程序的数据
+const discoveryApp = "ccsync-net"
+
+// announcement 局域网发现广播包
+type announcement struct {
+	App  string `json:"app"`
+	ID   string `json:"id"`   // 设备 ID
+	Port int    `json:"port"` // 同步服务端口
+}
+
+// Discovery 通过 UDP 广播在局域网内发现其他节点
+type Discovery struct {
+	deviceID string
+	port     int // 本机同步服务端口
+	udpPort  int // 广播端口
+	conn     *net.UDPConn
+	stopChan chan struct{}
+	lock     sync.Mutex
+
+	// 回调函数
+	OnPeer func(id, addr string)
+}
+
+// NewDiscovery 创建局域网发现服务
+func NewDiscovery(deviceID string, port, udpPort int) *Discovery {
+	return &Discovery{
+		deviceID: deviceID,
+		port:     port,
+		udpPort:  udpPort,
+	}
+}
+
+// Start 开始广播并监听其他节点
+func (d *Discovery) Start() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.conn != nil {
+		return nil
+	}
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: d.udpPort})
+	if err != nil {
+		return err
+	}
+	d.conn = conn
+	d.stopChan = make(chan struct{})
+
+	go d.announceLoop(conn, d.stopChan)
+	go d.listenLoop(conn)
+	return nil
+}
+
+// Stop 停止发现服务
+func (d *Discovery) Stop() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.conn == nil {
+		return
+	}
+	close(d.stopChan)
+	d.conn.Close()
+	d.conn = nil
+}
+
+func (d *Discovery) announceLoop(conn *net.UDPConn, stop chan struct{}) {
+	data, _ := json.Marshal(announcement{App: discoveryApp, ID: d.deviceID, Port: d.port})
+	dst := &net.UDPAddr{IP: net.IPv4bcast, Port: d.udpPort}
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if _, err := conn.WriteToUDP(data, dst); err != nil {
+			d.logger().Warn("discovery.broadcastFailed", "err", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (d *Discovery) listenLoop(conn *net.UDPConn) {
+	buf := make([]byte, 1024)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		var a announcement
+		if err := json.Unmarshal(buf[:n], &a); err != nil {
+			continue
+		}
+		if a.App != discoveryApp || a.ID == "" || a.ID == d.deviceID || a.Port <= 0 {
+			continue
+		}
+
+		if d.OnPeer != nil {
+			d.OnPeer(a.ID, net.JoinHostPort(from.IP.String(), strconv.Itoa(a.Port)))
+		}
+	}
+}
+
+func (d *Discovery) logger() *slog.Logger {
+	return logging.Component("discovery")
+}
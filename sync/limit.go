@@ -0,0 +1,177 @@
+package sync
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ccsync-net/i18n"
+)
+
+// Limits 服务端访问控制与限流设置，零值表示不限制
+type Limits struct {
+	Allow         []string // 允许连接的 CIDR 或 IP，为空时允许所有
+	Deny          []string // 拒绝连接的 CIDR 或 IP，优先于 Allow
+	MaxClients    int      // 最大同时连接数
+	ConnPerMinute int      // 每个 IP 每分钟最多建立的连接数
+	MsgPerSecond  int      // 每个客户端每秒最多发送的消息数
+}
+
+// RejectStats 被拒绝的连接与消息计数
+type RejectStats struct {
+	Unauthorized   uint64 `json:"unauthorized"`   // 认证失败
+	Denied         uint64 `json:"denied"`         // 不在允许列表或在拒绝列表中
+	TooManyClients uint64 `json:"tooManyClients"` // 超过最大连接数
+	ConnRateLimit  uint64 `json:"connRateLimit"`  // 连接过于频繁
+	MsgRateLimit   uint64 `json:"msgRateLimit"`   // 消息过于频繁而被丢弃
+}
+
+// rejectCounters 拒绝计数，使用原子操作
+type rejectCounters struct {
+	unauthorized   atomic.Uint64
+	denied         atomic.Uint64
+	tooManyClients atomic.Uint64
+	connRateLimit  atomic.Uint64
+	msgRateLimit   atomic.Uint64
+}
+
+func (c *rejectCounters) snapshot() RejectStats {
+	return RejectStats{
+		Unauthorized:   c.unauthorized.Load(),
+		Denied:         c.denied.Load(),
+		TooManyClients: c.tooManyClients.Load(),
+		ConnRateLimit:  c.connRateLimit.Load(),
+		MsgRateLimit:   c.msgRateLimit.Load(),
+	}
+}
+
+// accessPolicy 解析后的访问控制设置
+type accessPolicy struct {
+	allow         []*net.IPNet
+	deny          []*net.IPNet
+	maxClients    int
+	connPerMinute int
+	msgPerSecond  int
+}
+
+func newAccessPolicy(l Limits) (*accessPolicy, error) {
+	allow, err := ParseCIDRs(l.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := ParseCIDRs(l.Deny)
+	if err != nil {
+		return nil, err
+	}
+	return &accessPolicy{
+		allow:         allow,
+		deny:          deny,
+		maxClients:    l.MaxClients,
+		connPerMinute: l.ConnPerMinute,
+		msgPerSecond:  l.MsgPerSecond,
+	}, nil
+}
+
+// permits 检查 IP 是否允许连接
+func (p *accessPolicy) permits(ip net.IP) bool {
+	for _, n := range p.deny {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if len(p.allow) == 0 {
+		return true
+	}
+	for _, n := range p.allow {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCIDRs 解析 CIDR 列表，单个 IP 视为只包含该地址的网段
+func ParseCIDRs(list []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, i18n.Errorf("limit.invalidIP", "value", item)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, i18n.Errorf("limit.invalidCIDR", "value", item)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// bucket 令牌桶
+type bucket struct {
+	rate   float64 // 每秒补充的令牌数
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rate float64, burst int) *bucket {
+	return &bucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+func (b *bucket) allow() bool {
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// ipLimiter 按 IP 限制连接频率
+type ipLimiter struct {
+	buckets map[string]*bucket
+	lock    sync.Mutex
+}
+
+func newIPLimiter() *ipLimiter {
+	return &ipLimiter{buckets: make(map[string]*bucket)}
+}
+
+func (l *ipLimiter) allow(ip string, perMinute int) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	// 清理已补满的桶，避免长期运行时无限增长
+	if len(l.buckets) > 1024 {
+		for k, b := range l.buckets {
+			if time.Since(b.last) > time.Minute {
+				delete(l.buckets, k)
+			}
+		}
+	}
+
+	b, ok := l.buckets[ip]
+	if !ok || b.burst != float64(perMinute) {
+		b = newBucket(float64(perMinute)/60, perMinute)
+		l.buckets[ip] = b
+	}
+	return b.allow()
+}
@@ -0,0 +1,140 @@
+package sync
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAccessPolicyPermits(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow []string
+		deny  []string
+		ip    string
+		want  bool
+	}{
+		{name: "no lists", ip: "203.0.113.7", want: true},
+		{name: "allowed cidr", allow: []string{"192.168.1.0/24"}, ip: "192.168.1.20", want: true},
+		{name: "outside allow", allow: []string{"192.168.1.0/24"}, ip: "192.168.2.20", want: false},
+		{name: "single ip", allow: []string{"10.0.0.5"}, ip: "10.0.0.5", want: true},
+		{name: "single ip neighbour", allow: []string{"10.0.0.5"}, ip: "10.0.0.6", want: false},
+		{name: "denied", deny: []string{"10.0.0.0/8"}, ip: "10.1.2.3", want: false},
+		{name: "deny wins", allow: []string{"10.0.0.0/8"}, deny: []string{"10.0.0.5"}, ip: "10.0.0.5", want: false},
+		{name: "deny other", allow: []string{"10.0.0.0/8"}, deny: []string{"10.0.0.5"}, ip: "10.0.0.6", want: true},
+		{name: "ipv6 cidr", allow: []string{"fd00::/8"}, ip: "fd12::1", want: true},
+		{name: "ipv6 outside", allow: []string{"fd00::/8"}, ip: "2001:db8::1", want: false},
+		{name: "mapped ipv4", allow: []string{" 127.0.0.1 "}, ip: "::ffff:127.0.0.1", want: true},
+	}
+	for _, tt := range tests {
+		p, err := newAccessPolicy(Limits{Allow: tt.allow, Deny: tt.deny})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := p.permits(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("%s: permits(%s) = %v, want %v", tt.name, tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestParseCIDRsInvalid(t *testing.T) {
+	for _, item := range []string{"", "not-an-ip", "10.0.0.0/33", "10.0.0/8", "fd00::/129"} {
+		if _, err := ParseCIDRs([]string{item}); err == nil {
+			t.Errorf("ParseCIDRs(%q) = nil error", item)
+		}
+	}
+	if _, err := newAccessPolicy(Limits{Deny: []string{"bad"}}); err == nil {
+		t.Error("newAccessPolicy accepted an invalid deny entry")
+	}
+}
+
+func TestBucket(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		burst   int
+		elapsed time.Duration // 用完令牌后经过的时间
+		want    int           // 之后允许的次数
+	}{
+		{name: "no refill", rate: 1, burst: 3, want: 0},
+		{name: "partial refill", rate: 1, burst: 3, elapsed: 1500 * time.Millisecond, want: 1},
+		{name: "refill", rate: 2, burst: 3, elapsed: time.Second, want: 2},
+		{name: "capped at burst", rate: 10, burst: 3, elapsed: time.Minute, want: 3},
+	}
+	for _, tt := range tests {
+		b := newBucket(tt.rate, tt.burst)
+		for i := 0; i < tt.burst; i++ {
+			if !b.allow() {
+				t.Fatalf("%s: request %d within burst rejected", tt.name, i)
+			}
+		}
+		b.last = b.last.Add(-tt.elapsed)
+		if got := allowed(b.allow, tt.burst+1); got != tt.want {
+			t.Errorf("%s: allowed %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIPLimiter(t *testing.T) {
+	l := newIPLimiter()
+	if got := allowed(func() bool { return l.allow("10.0.0.1", 3) }, 5); got != 3 {
+		t.Errorf("10.0.0.1 allowed %d, want 3", got)
+	}
+	// 每个 IP 单独计数
+	if !l.allow("10.0.0.2", 3) {
+		t.Error("10.0.0.2 limited by another IP")
+	}
+	// 修改限制后重新计数
+	if !l.allow("10.0.0.1", 5) {
+		t.Error("limit change not applied")
+	}
+}
+
+func TestAdmitLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		want   []int // 依次连接时 admit 返回的状态码
+		stats  RejectStats
+	}{
+		{name: "no limits", want: []int{0, 0, 0}},
+		{name: "allowed", limits: Limits{Allow: []string{"192.0.2.0/24"}}, want: []int{0}},
+		{name: "not allowed", limits: Limits{Allow: []string{"10.0.0.0/8"}}, want: []int{http.StatusForbidden}, stats: RejectStats{Denied: 1}},
+		{name: "denied", limits: Limits{Deny: []string{"192.0.2.1"}}, want: []int{http.StatusForbidden}, stats: RejectStats{Denied: 1}},
+		{
+			name:   "conn rate",
+			limits: Limits{ConnPerMinute: 2},
+			want:   []int{0, 0, http.StatusTooManyRequests, http.StatusTooManyRequests},
+			stats:  RejectStats{ConnRateLimit: 2},
+		},
+	}
+	for _, tt := range tests {
+		s := NewServer()
+		if err := s.SetLimits(tt.limits); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for i, want := range tt.want {
+			// httptest 请求的来源地址为 192.0.2.1
+			r := httptest.NewRequest("GET", "http://localhost:8765/ws", nil)
+			if code, _ := s.admit(r); code != want {
+				t.Errorf("%s: connection %d: admit = %d, want %d", tt.name, i, code, want)
+			}
+		}
+		if got := s.RejectStats(); got != tt.stats {
+			t.Errorf("%s: stats = %+v, want %+v", tt.name, got, tt.stats)
+		}
+	}
+}
+
+// allowed 调用 n 次 allow，返回通过的次数
+func allowed(allow func() bool, n int) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		if allow() {
+			count++
+		}
+	}
+	return count
+}
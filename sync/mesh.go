@@ -0,0 +1,293 @@
+package sync
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"ccsync-net/logging"
+)
+
+// meshPeerTimeout 超过该时间未收到广播且未连接的节点将被移除
+const meshPeerTimeout = 30 * time.Second
+
+// meshPeer 网状网络中由本机主动连接的节点
+type meshPeer struct {
+	client   *Client
+	addr     string
+	id       string // 连接后对方已证明的设备 ID
+	lastSeen time.Time
+	static   bool // 手动配置的节点，不会因超时被移除
+}
+
+// Mesh 网状网络的主动连接部分。
+// 每个节点同时运行 Server 接受连接，并通过 Mesh 连接发现的其他节点；
+// 两个节点之间只由设备 ID 较小的一方发起连接，避免重复连接。
+// 设置了密钥时，节点之间以挑战应答互相证明持有密钥，证明通过前不交换内容。
+type Mesh struct {
+	deviceID  string
+	secret    string
+	port      int
+	peers     map[string]*meshPeer
+	discovery *Discovery
+	stopChan  chan struct{}
+	running   bool
+	lock      sync.Mutex
+
+	MeshHandlers
+}
+
+// MeshHandlers 网状网络的回调函数
+type MeshHandlers struct {
+	OnClipboardReceived func(msg *Message, from *Client)
+	OnPeersChanged      func(count int)
+	OnPeerFound         func(id, addr string) // 发现新节点，不包括地址变化
+}
+
+// SetHandlers 设置回调函数，应在 Start 之前调用
+func (m *Mesh) SetHandlers(h MeshHandlers) {
+	m.MeshHandlers = h
+}
+
+// NewMesh 创建网状网络
+func NewMesh(deviceID string) *Mesh {
+	return &Mesh{
+		deviceID: deviceID,
+		peers:    make(map[string]*meshPeer),
+	}
+}
+
+// Start 开始发现并连接其他节点。
+// port 为本机 Server 的端口，discoveryPort 为 0 时不进行局域网发现，仅连接 static 中的地址。
+func (m *Mesh) Start(port, discoveryPort int, secret string, static []string) error {
+	m.lock.Lock()
+	if m.running {
+		m.lock.Unlock()
+		return nil
+	}
+	m.running = true
+	m.secret = secret
+	m.port = port
+	m.stopChan = make(chan struct{})
+	for _, addr := range static {
+		m.addPeerLocked("addr:"+addr, addr, true)
+	}
+	stop := m.stopChan
+	m.lock.Unlock()
+
+	if discoveryPort > 0 {
+		d := NewDiscovery(m.deviceID, port, discoveryPort)
+		d.OnPeer = m.Announce
+		if err := d.Start(); err != nil {
+			m.logger().Warn("mesh.discoveryFailed", "err", err)
+		} else {
+			m.lock.Lock()
+			m.discovery = d
+			m.lock.Unlock()
+			m.logger().Info("mesh.discoveryStarted", "port", discoveryPort)
+		}
+	}
+
+	go m.expireLoop(stop)
+	return nil
+}
+
+// Stop 断开所有节点并停止发现
+func (m *Mesh) Stop() {
+	m.lock.Lock()
+	if !m.running {
+		m.lock.Unlock()
+		return
+	}
+	m.running = false
+	close(m.stopChan)
+
+	discovery := m.discovery
+	m.discovery = nil
+	peers := m.peers
+	m.peers = make(map[string]*meshPeer)
+	m.lock.Unlock()
+
+	if discovery != nil {
+		discovery.Stop()
+	}
+	for _, p := range peers {
+		p.client.Disconnect()
+	}
+	m.notifyPeers()
+}
+
+// IsRunning 检查是否运行中
+func (m *Mesh) IsRunning() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.running
+}
+
+// ConnectedCount 返回已连接的节点数
+func (m *Mesh) ConnectedCount() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	count := 0
+	for _, p := range m.peers {
+		if p.client.IsConnected() {
+			count++
+		}
+	}
+	return count
+}
+
+// Send 将消息发送给除 except 外所有已连接的节点
+func (m *Mesh) Send(msg *Message, except *Client) {
+	m.lock.Lock()
+	clients := make([]*Client, 0, len(m.peers))
+	for _, p := range m.peers {
+		if p.client != except {
+			clients = append(clients, p.client)
+		}
+	}
+	m.lock.Unlock()
+
+	for _, c := range clients {
+		if c.IsConnected() {
+			if err := c.SendMessage(msg); err != nil {
+				m.logger().Warn("sync.sendFailed", "err", err)
+			}
+		}
+	}
+}
+
+// Announce 添加局域网发现或对方节点告知的节点，只连接设备 ID 较大的节点
+func (m *Mesh) Announce(id, addr string) {
+	// 只由设备 ID 较小的一方发起连接
+	if m.deviceID >= id {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if !m.running {
+		return
+	}
+
+	for _, p := range m.peers {
+		if p.static && p.id == id {
+			// 已通过手动配置的地址连接
+			p.lastSeen = time.Now()
+			return
+		}
+	}
+
+	if p, ok := m.peers[id]; ok {
+		p.lastSeen = time.Now()
+		if p.addr == addr {
+			return
+		}
+		// 节点地址变化，重新连接
+		m.logger().Info("mesh.peerAddrChanged", "peer", id, "addr", addr)
+		p.addr = addr
+		go p.client.ReconnectEndpoints([]Endpoint{m.endpoint(id, addr)})
+		return
+	}
+
+	m.logger().Info("mesh.peerFound", "peer", id, "addr", addr)
+	m.addPeerLocked(id, addr, false)
+	if m.OnPeerFound != nil {
+		go m.OnPeerFound(id, addr)
+	}
+}
+
+func (m *Mesh) addPeerLocked(key, addr string, static bool) {
+	c := NewClient()
+	c.OnClipboardReceived = func(msg *Message) {
+		if m.OnClipboardReceived != nil {
+			m.OnClipboardReceived(msg, c)
+		}
+	}
+	c.OnConnected = func(Endpoint) {
+		m.peerConnected(key, c)
+		m.notifyPeers()
+	}
+	c.OnDisconnected = m.notifyPeers
+	c.component = "mesh"
+
+	m.peers[key] = &meshPeer{
+		client:   c,
+		addr:     addr,
+		lastSeen: time.Now(),
+		static:   static,
+	}
+	c.ConnectEndpoints([]Endpoint{m.endpoint(key, addr)})
+}
+
+// endpoint 返回连接其他节点的端点
+func (m *Mesh) endpoint(name, addr string) Endpoint {
+	return Endpoint{
+		Name:     name,
+		Address:  addr,
+		Secret:   m.secret,
+		DeviceID: m.deviceID,
+		Mesh:     true,
+		MeshPort: m.port,
+	}
+}
+
+// peerConnected 记录对方的设备 ID。
+// 同一节点既是手动配置的又是发现的时，只保留手动配置的连接。
+func (m *Mesh) peerConnected(key string, c *Client) {
+	id := c.RemoteID()
+
+	m.lock.Lock()
+	p, ok := m.peers[key]
+	if !ok || p.client != c {
+		m.lock.Unlock()
+		return
+	}
+	p.id = id
+
+	var dup *Client
+	if other, ok := m.peers[id]; ok && p.static && other != p {
+		delete(m.peers, id)
+		dup = other.client
+	}
+	m.lock.Unlock()
+
+	if dup != nil {
+		dup.Disconnect()
+	}
+}
+
+func (m *Mesh) expireLoop(stop chan struct{}) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.lock.Lock()
+			for key, p := range m.peers {
+				if p.static || p.client.IsConnected() || time.Since(p.lastSeen) < meshPeerTimeout {
+					continue
+				}
+				m.logger().Info("mesh.peerOffline", "peer", key)
+				delete(m.peers, key)
+				go p.client.Disconnect()
+			}
+			m.lock.Unlock()
+		}
+	}
+}
+
+func (m *Mesh) notifyPeers() {
+	if m.OnPeersChanged != nil {
+		m.OnPeersChanged(m.ConnectedCount())
+	}
+}
+
+func (m *Mesh) logger() *slog.Logger {
+	return logging.Component("mesh")
+}
@@ -0,0 +1,159 @@
+package sync
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ccsync-net/i18n"
+
+	"github.com/gorilla/websocket"
+)
+
+// 网状网络节点之间的挑战应答认证。
+// 连接方在请求中携带随机数，服务端在升级响应中返回自己的随机数、设备 ID 与证明，
+// 连接方校验后再发送自己的证明。双方都不发送密钥本身，证明通过前不交换剪贴板内容。
+// 双方因此都得知对方的设备 ID，设备 ID 较大的一方让步，由较小的一方发起连接。
+const (
+	HeaderMeshNonce = "X-Mesh-Nonce"
+	HeaderMeshProof = "X-Mesh-Proof"
+	HeaderMeshPort  = "X-Mesh-Port" // 连接方的同步服务端口，让步时由对方反向连接
+)
+
+// TypeMeshAuth 连接方发送的认证消息，必须是连接后的第一条消息
+const TypeMeshAuth MessageType = "mesh-auth"
+
+// meshAuthTimeout 服务端等待连接方认证消息的时间
+const meshAuthTimeout = 10 * time.Second
+
+// meshYieldInterval 让步后再次通知对方的间隔
+const meshYieldInterval = 10 * time.Second
+
+// errMeshYield 对方设备 ID 较小，由对方发起连接
+var errMeshYield = errors.New("mesh: yield to peer")
+
+const (
+	meshRoleServer = "server"
+	meshRoleClient = "client"
+)
+
+// meshAuth 连接方的认证消息
+type meshAuth struct {
+	Type  MessageType `json:"type"`
+	Proof string      `json:"proof"`
+	// Yield 连接方设备 ID 较大，只用于告知对方自己的地址，随后断开
+	Yield bool `json:"yield,omitempty"`
+}
+
+// meshProof 计算一方持有密钥的证明，包含双方随机数与该方的设备 ID，不能被重放或反射
+func meshProof(secret, role, clientNonce, serverNonce, id string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(role + "\n" + clientNonce + "\n" + serverNonce + "\n" + id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// proofEqual 以固定时间比较证明
+func proofEqual(a, b string) bool {
+	return hmac.Equal([]byte(a), []byte(b))
+}
+
+// dialMesh 以挑战应答方式连接其他节点，返回对方已证明的设备 ID。
+// 对方设备 ID 较小时只发送让步通知并返回 errMeshYield。
+func (e Endpoint) dialMesh() (*websocket.Conn, string, error) {
+	clientNonce := NewMessageID()
+	header := http.Header{}
+	header.Set(HeaderDeviceID, e.DeviceID)
+	header.Set(HeaderMeshNonce, clientNonce)
+	header.Set(HeaderMeshPort, strconv.Itoa(e.MeshPort))
+
+	conn, resp, err := e.dialer().Dial(e.URL(), header)
+	if err != nil {
+		return nil, "", err
+	}
+
+	serverNonce := resp.Header.Get(HeaderMeshNonce)
+	id := resp.Header.Get(HeaderDeviceID)
+	expected := meshProof(e.Secret, meshRoleServer, clientNonce, serverNonce, id)
+	if serverNonce == "" || id == "" || !proofEqual(resp.Header.Get(HeaderMeshProof), expected) {
+		conn.Close()
+		return nil, "", i18n.Errorf("mesh.proofMismatch")
+	}
+
+	yield := e.DeviceID >= id
+	auth, _ := json.Marshal(meshAuth{
+		Type:  TypeMeshAuth,
+		Proof: meshProof(e.Secret, meshRoleClient, clientNonce, serverNonce, e.DeviceID),
+		Yield: yield,
+	})
+	if err := conn.WriteMessage(websocket.TextMessage, auth); err != nil {
+		conn.Close()
+		return nil, "", err
+	}
+	if yield {
+		conn.Close()
+		return nil, id, errMeshYield
+	}
+	return conn, id, nil
+}
+
+// meshRequested 检查请求是否为节点间的挑战应答连接。
+// 未设置密钥时只用于交换设备 ID，此时要求配对的服务端不接受节点连接；
+// 未设置本机设备 ID 时按普通连接处理。
+func (s *Server) meshRequested(r *http.Request) bool {
+	s.runningLock.RLock()
+	ok := s.deviceID != "" && (s.secret != "" || !s.requirePair)
+	s.runningLock.RUnlock()
+	return ok && r.Header.Get(HeaderMeshNonce) != "" && requestDeviceID(r) != ""
+}
+
+// meshChallenge 生成本机的随机数与证明，作为升级响应头返回给连接方
+func (s *Server) meshChallenge(r *http.Request) (http.Header, string) {
+	s.runningLock.RLock()
+	secret, deviceID := s.secret, s.deviceID
+	s.runningLock.RUnlock()
+
+	serverNonce := NewMessageID()
+	header := http.Header{}
+	header.Set(HeaderMeshNonce, serverNonce)
+	header.Set(HeaderDeviceID, deviceID)
+	header.Set(HeaderMeshProof, meshProof(secret, meshRoleServer, r.Header.Get(HeaderMeshNonce), serverNonce, deviceID))
+	return header, serverNonce
+}
+
+// verifyMesh 读取并校验连接方的认证消息，通过且不是让步通知时返回 true
+func (s *Server) verifyMesh(conn *websocket.Conn, r *http.Request, serverNonce string) bool {
+	s.runningLock.RLock()
+	secret := s.secret
+	s.runningLock.RUnlock()
+
+	conn.SetReadDeadline(time.Now().Add(meshAuthTimeout))
+	_, data, err := conn.ReadMessage()
+	conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		return false
+	}
+
+	var auth meshAuth
+	id := requestDeviceID(r)
+	expected := meshProof(secret, meshRoleClient, r.Header.Get(HeaderMeshNonce), serverNonce, id)
+	if json.Unmarshal(data, &auth) != nil || auth.Type != TypeMeshAuth || !proofEqual(auth.Proof, expected) {
+		s.rejects.unauthorized.Add(1)
+		s.logger().Warn("server.connRejected", "addr", r.RemoteAddr, "reason", i18n.T("reject.unauthorized"))
+		return false
+	}
+
+	if auth.Yield {
+		port, err := strconv.Atoi(r.Header.Get(HeaderMeshPort))
+		if err == nil && port > 0 && s.OnMeshPeer != nil {
+			s.OnMeshPeer(id, net.JoinHostPort(remoteHost(r), strconv.Itoa(port)))
+		}
+		return false
+	}
+	return true
+}
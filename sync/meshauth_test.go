@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestMeshHandshake(t *testing.T) {
+	s := NewServer()
+	s.SetSecret("s3cret")
+	s.SetDeviceID("node-b")
+	received := make(chan *Message, 1)
+	announced := make(chan string, 1)
+	s.SetHandlers(ServerHandlers{
+		OnClipboardReceived: func(msg *Message) { received <- msg },
+		OnMeshPeer:          func(id, addr string) { announced <- id + "@" + addr },
+	})
+
+	ts := httptest.NewServer(http.HandlerFunc(s.handleConnection))
+	defer ts.Close()
+	addr := strings.TrimPrefix(ts.URL, "http://")
+
+	tests := []struct {
+		name     string
+		deviceID string
+		secret   string
+		wantID   string
+		wantErr  bool
+		announce string
+	}{
+		{name: "smaller id connects", deviceID: "node-a", secret: "s3cret", wantID: "node-b"},
+		{name: "wrong secret", deviceID: "node-a", secret: "guess", wantErr: true},
+		{name: "larger id yields", deviceID: "node-c", secret: "s3cret", wantID: "node-b", wantErr: true, announce: "node-c@127.0.0.1:9000"},
+	}
+	for _, tt := range tests {
+		ep := Endpoint{Address: addr, Secret: tt.secret, DeviceID: tt.deviceID, Mesh: true, MeshPort: 9000}
+		conn, id, err := ep.dial()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: err = %v", tt.name, err)
+		}
+		if id != tt.wantID {
+			t.Errorf("%s: id = %q, want %q", tt.name, id, tt.wantID)
+		}
+		if tt.announce != "" {
+			if !errors.Is(err, errMeshYield) {
+				t.Errorf("%s: err = %v, want yield", tt.name, err)
+			}
+			select {
+			case got := <-announced:
+				if got != tt.announce {
+					t.Errorf("%s: announced %q, want %q", tt.name, got, tt.announce)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("%s: peer not announced", tt.name)
+			}
+		}
+		if conn == nil {
+			continue
+		}
+		if err := conn.WriteJSON(NewClipboardMessage("x", "test")); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-received:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: message not received", tt.name)
+		}
+		conn.Close()
+	}
+}
+
+func TestMeshHandshakeRejectsForgedProof(t *testing.T) {
+	s := NewServer()
+	s.SetSecret("s3cret")
+	s.SetDeviceID("node-b")
+	received := make(chan *Message, 1)
+	s.SetHandlers(ServerHandlers{OnClipboardReceived: func(msg *Message) { received <- msg }})
+
+	ts := httptest.NewServer(http.HandlerFunc(s.handleConnection))
+	defer ts.Close()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	header := http.Header{}
+	header.Set(HeaderDeviceID, "node-a")
+	header.Set(HeaderMeshNonce, "nonce")
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if resp.Header.Get(HeaderMeshProof) == "" {
+		t.Fatal("server sent no proof")
+	}
+	if strings.Contains(resp.Header.Get(HeaderMeshProof), "s3cret") {
+		t.Fatal("server sent the secret")
+	}
+
+	conn.WriteJSON(meshAuth{Type: TypeMeshAuth, Proof: "forged"})
+	conn.WriteJSON(NewClipboardMessage("x", "test"))
+	select {
+	case <-received:
+		t.Fatal("message accepted without a valid proof")
+	case <-time.After(200 * time.Millisecond):
+	}
+	if s.RejectStats().Unauthorized != 1 {
+		t.Errorf("unauthorized = %d, want 1", s.RejectStats().Unauthorized)
+	}
+}
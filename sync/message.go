@@ -1,6 +1,10 @@
 package sync
 
-import "time"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
 
 // MessageType 消息类型
 type MessageType string
@@ -13,22 +17,45 @@ const (
 
 // Message WebSocket 通信消息
 type Message struct {
-	Type      MessageType `json:"type"`      // 消息类型
-	Content   string      `json:"content"`   // 剪贴板内容
-	Timestamp int64       `json:"timestamp"` // 时间戳
-	Source    string      `json:"source"`    // 来源标识
+	Type      MessageType `json:"type"`                // 消息类型
+	ID        string      `json:"id,omitempty"`        // 消息唯一标识
+	Origin    string      `json:"origin,omitempty"`    // 最初产生该内容的设备 ID
+	Content   string      `json:"content"`             // 剪贴板内容
+	Timestamp int64       `json:"timestamp"`           // 时间戳
+	Source    string      `json:"source"`              // 来源标识
+	Device    string      `json:"device,omitempty"`    // 发送方设备名称
+	Sensitive bool        `json:"sensitive,omitempty"` // 发送方标记为敏感内容
+
+	// VerifiedDevice 转发该消息的服务端通过设备凭据认证的发送方设备 ID。
+	// 由服务端填写，发送方自带的值会被覆盖。
+	VerifiedDevice string `json:"verifiedDevice,omitempty"`
+
+	// AuthDevice 经过认证的发送方设备 ID，不在网络上传输：
+	// 本机服务端收到时为本机认证的结果，客户端收到时为上游服务端转发的 VerifiedDevice。
+	// 与发送方自报的 Origin、Device 不同，可用于信任判断。
+	AuthDevice string `json:"-"`
 }
 
 // NewClipboardMessage 创建剪贴板消息
 func NewClipboardMessage(content, source string) *Message {
 	return &Message{
 		Type:      TypeClipboard,
+		ID:        NewMessageID(),
 		Content:   content,
 		Timestamp: time.Now().UnixMilli(),
 		Source:    source,
 	}
 }
 
+// NewMessageID 生成随机消息 ID
+func NewMessageID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString([]byte(time.Now().String()))
+	}
+	return hex.EncodeToString(b)
+}
+
 // NewPingMessage 创建心跳消息
 func NewPingMessage() *Message {
 	return &Message{
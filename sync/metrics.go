@@ -0,0 +1,255 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets 消息延迟直方图的上界 (秒)
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogram 固定分桶的直方图，使用原子操作
+type histogram struct {
+	buckets []float64
+	counts  []atomic.Uint64 // 各分桶 (非累计) 的计数，最后一个为 +Inf
+	sumBits atomic.Uint64   // float64 总和的位表示
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{
+		buckets: buckets,
+		counts:  make([]atomic.Uint64, len(buckets)+1),
+	}
+}
+
+func (h *histogram) observe(v float64) {
+	i := 0
+	for i < len(h.buckets) && v > h.buckets[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	for {
+		old := h.sumBits.Load()
+		sum := math.Float64bits(math.Float64frombits(old) + v)
+		if h.sumBits.CompareAndSwap(old, sum) {
+			return
+		}
+	}
+}
+
+// observeLatency 记录消息从产生到收到的时间。
+// 时间戳由发送方生成，结果包含设备间的时钟偏差，负值按 0 计。
+func (h *histogram) observeLatency(msg *Message) {
+	if msg.Timestamp <= 0 {
+		return
+	}
+	d := time.Since(time.UnixMilli(msg.Timestamp)).Seconds()
+	h.observe(math.Max(d, 0))
+}
+
+// serverMetrics 服务端统计
+type serverMetrics struct {
+	received    atomic.Uint64 // 收到的剪贴板消息，包括通过 API 发送的
+	relayed     atomic.Uint64 // 转发给其他客户端的消息 (按接收方计)
+	broadcast   atomic.Uint64 // 本机广播给客户端的消息 (按接收方计)
+	duplicates  atomic.Uint64 // 已处理过而丢弃的消息
+	invalid     atomic.Uint64 // 无法解析而丢弃的消息
+	bytesIn     atomic.Uint64
+	bytesOut    atomic.Uint64
+	writeErrors atomic.Uint64
+	connections atomic.Uint64 // 累计接受的连接
+	latency     *histogram
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{latency: newHistogram(latencyBuckets)}
+}
+
+// clientMetrics 单个客户端的统计
+type clientMetrics struct {
+	connected   atomic.Int64  // 已连接时为 1
+	reconnects  atomic.Uint64 // 断线后重新连接成功的次数
+	sent        atomic.Uint64
+	received    atomic.Uint64
+	bytesIn     atomic.Uint64
+	bytesOut    atomic.Uint64
+	writeErrors atomic.Uint64
+	latency     *histogram
+}
+
+func newClientMetrics() *clientMetrics {
+	return &clientMetrics{latency: newHistogram(latencyBuckets)}
+}
+
+// activeClients 本进程内处于连接或重连状态的客户端，各自的统计按 client 标签输出
+var activeClients = struct {
+	clients map[*Client]struct{}
+	lock    sync.Mutex
+}{clients: make(map[*Client]struct{})}
+
+func registerClient(c *Client) {
+	activeClients.lock.Lock()
+	activeClients.clients[c] = struct{}{}
+	activeClients.lock.Unlock()
+}
+
+func unregisterClient(c *Client) {
+	activeClients.lock.Lock()
+	delete(activeClients.clients, c)
+	activeClients.lock.Unlock()
+}
+
+// clientSeries 客户端统计及其标签
+type clientSeries struct {
+	labels string
+	stats  *clientMetrics
+}
+
+// clientSnapshot 返回所有活动客户端的统计，按标签排序
+func clientSnapshot() []clientSeries {
+	activeClients.lock.Lock()
+	series := make([]clientSeries, 0, len(activeClients.clients))
+	for c := range activeClients.clients {
+		series = append(series, clientSeries{labels: "client=" + strconv.Quote(c.metricsLabel()), stats: c.stats})
+	}
+	activeClients.lock.Unlock()
+
+	slices.SortFunc(series, func(a, b clientSeries) int { return strings.Compare(a.labels, b.labels) })
+	return series
+}
+
+// handleMetrics 以 Prometheus 文本格式输出统计。
+// 受访问控制列表限制；设置了 API 令牌时需携带该令牌，否则与同步连接相同，校验服务端密钥或设备凭据。
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !s.permitted(r) {
+		s.rejects.denied.Add(1)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	authorized := s.authorized
+	if s.apiEnabled() {
+		authorized = s.apiAuthorized
+	}
+	if !authorized(r) {
+		s.rejects.unauthorized.Add(1)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.writeMetrics(w)
+}
+
+// writeMetrics 输出服务端及本进程客户端的统计
+func (s *Server) writeMetrics(w io.Writer) {
+	m := s.metrics
+	rejects := s.RejectStats()
+
+	gauge(w, "ccsync_server_clients", "Number of connected clients.", float64(s.GetClientCount()))
+	counter(w, "ccsync_server_connections_total", "Accepted client connections.", m.connections.Load())
+
+	header(w, "ccsync_server_connections_rejected_total", "counter", "Rejected client connections by reason.")
+	sample(w, "ccsync_server_connections_rejected_total", `reason="unauthorized"`, float64(rejects.Unauthorized))
+	sample(w, "ccsync_server_connections_rejected_total", `reason="denied"`, float64(rejects.Denied))
+	sample(w, "ccsync_server_connections_rejected_total", `reason="too_many_clients"`, float64(rejects.TooManyClients))
+	sample(w, "ccsync_server_connections_rejected_total", `reason="conn_rate_limit"`, float64(rejects.ConnRateLimit))
+
+	counter(w, "ccsync_server_messages_received_total", "Clipboard messages received from clients.", m.received.Load())
+
+	header(w, "ccsync_server_messages_sent_total", "counter", "Clipboard messages sent to clients, per recipient.")
+	sample(w, "ccsync_server_messages_sent_total", `kind="relayed"`, float64(m.relayed.Load()))
+	sample(w, "ccsync_server_messages_sent_total", `kind="broadcast"`, float64(m.broadcast.Load()))
+
+	header(w, "ccsync_server_messages_dropped_total", "counter", "Clipboard messages dropped by reason.")
+	sample(w, "ccsync_server_messages_dropped_total", `reason="duplicate"`, float64(m.duplicates.Load()))
+	sample(w, "ccsync_server_messages_dropped_total", `reason="invalid"`, float64(m.invalid.Load()))
+	sample(w, "ccsync_server_messages_dropped_total", `reason="rate_limit"`, float64(rejects.MsgRateLimit))
+
+	counter(w, "ccsync_server_received_bytes_total", "Bytes received from clients.", m.bytesIn.Load())
+	counter(w, "ccsync_server_sent_bytes_total", "Bytes sent to clients.", m.bytesOut.Load())
+	counter(w, "ccsync_server_write_errors_total", "Failed writes to clients.", m.writeErrors.Load())
+	header(w, "ccsync_server_message_latency_seconds", "histogram",
+		"Time from clip creation on the sender to receipt by the server, including clock skew.")
+	writeHistogram(w, "ccsync_server_message_latency_seconds", "", m.latency)
+
+	writeClientMetrics(w, clientSnapshot())
+}
+
+// writeClientMetrics 输出本进程各客户端的统计，每个客户端一组带 client 标签的样本
+func writeClientMetrics(w io.Writer, clients []clientSeries) {
+	if len(clients) == 0 {
+		return
+	}
+	each := func(name, typ, help string, value func(c *clientMetrics) float64) {
+		header(w, name, typ, help)
+		for _, c := range clients {
+			sample(w, name, c.labels, value(c.stats))
+		}
+	}
+	each("ccsync_client_connected", "gauge", "Whether the outgoing client is connected.",
+		func(c *clientMetrics) float64 { return float64(c.connected.Load()) })
+	each("ccsync_client_reconnects_total", "counter", "Successful reconnections after a lost connection.",
+		func(c *clientMetrics) float64 { return float64(c.reconnects.Load()) })
+	each("ccsync_client_messages_sent_total", "counter", "Clipboard messages sent by the client.",
+		func(c *clientMetrics) float64 { return float64(c.sent.Load()) })
+	each("ccsync_client_messages_received_total", "counter", "Clipboard messages received by the client.",
+		func(c *clientMetrics) float64 { return float64(c.received.Load()) })
+	each("ccsync_client_received_bytes_total", "counter", "Bytes received by the client.",
+		func(c *clientMetrics) float64 { return float64(c.bytesIn.Load()) })
+	each("ccsync_client_sent_bytes_total", "counter", "Bytes sent by the client.",
+		func(c *clientMetrics) float64 { return float64(c.bytesOut.Load()) })
+	each("ccsync_client_write_errors_total", "counter", "Failed writes by the client.",
+		func(c *clientMetrics) float64 { return float64(c.writeErrors.Load()) })
+
+	header(w, "ccsync_client_message_latency_seconds", "histogram",
+		"Time from clip creation on the sender to receipt by the client, including clock skew.")
+	for _, c := range clients {
+		writeHistogram(w, "ccsync_client_message_latency_seconds", c.labels, c.stats.latency)
+	}
+}
+
+func header(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func sample(w io.Writer, name, labels string, v float64) {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s %g\n", name, v)
+}
+
+func counter(w io.Writer, name, help string, v uint64) {
+	header(w, name, "counter", help)
+	fmt.Fprintf(w, "%s %d\n", name, v)
+}
+
+func gauge(w io.Writer, name, help string, v float64) {
+	header(w, name, "gauge", help)
+	sample(w, name, "", v)
+}
+
+// writeHistogram 输出直方图的样本，labels 为空或形如 client="x"，头部由调用方输出
+func writeHistogram(w io.Writer, name, labels string, h *histogram) {
+	prefix := ""
+	if labels != "" {
+		prefix = labels + ","
+	}
+	var cumulative uint64
+	for i, le := range h.buckets {
+		cumulative += h.counts[i].Load()
+		sample(w, name+"_bucket", fmt.Sprintf(`%sle="%g"`, prefix, le), float64(cumulative))
+	}
+	cumulative += h.counts[len(h.buckets)].Load()
+	sample(w, name+"_bucket", prefix+`le="+Inf"`, float64(cumulative))
+	sample(w, name+"_sum", labels, math.Float64frombits(h.sumBits.Load()))
+	sample(w, name+"_count", labels, float64(cumulative))
+}
@@ -0,0 +1,378 @@
+package sync
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"ccsync-net/i18n"
+
+	"github.com/gorilla/websocket"
+)
+
+// pairingTimeout 等待服务端确认配对的最长时间
+const pairingTimeout = 2 * time.Minute
+
+// 配对消息类型。
+// 双方交换 X25519 公钥，客户端先发送公钥的哈希 (承诺)，收到服务端公钥后才公开自己的公钥，
+// 中间人无法在得知双方公钥后再选择自己的公钥去凑出相同的校验码。
+// 设备凭据使用协商出的密钥加密后发送。
+const (
+	TypePairRequest   MessageType = "pair_request"   // 客户端请求配对，携带公钥承诺
+	TypePairChallenge MessageType = "pair_challenge" // 服务端返回公钥
+	TypePairKey       MessageType = "pair_key"       // 客户端公开公钥，双方据此计算校验码
+	TypePairResult    MessageType = "pair_result"    // 服务端返回配对结果与加密的设备凭据
+)
+
+// pairMessage 配对过程中的消息
+type pairMessage struct {
+	Type       MessageType `json:"type"`
+	DeviceID   string      `json:"deviceId,omitempty"`
+	DeviceName string      `json:"deviceName,omitempty"`
+	Commit     string      `json:"commit,omitempty"` // 客户端公钥的 SHA-256
+	Key        string      `json:"key,omitempty"`    // X25519 公钥 (十六进制)
+	Approved   bool        `json:"approved,omitempty"`
+	Token      string      `json:"token,omitempty"` // 加密的设备凭据 (十六进制)
+}
+
+// PairRequest 待确认的配对请求
+type PairRequest struct {
+	ID         string `json:"id"`
+	DeviceID   string `json:"deviceId"`
+	DeviceName string `json:"deviceName"`
+	Addr       string `json:"addr"`
+	Code       string `json:"code"` // 校验码，双方界面显示一致时才应批准
+}
+
+// PairedDevice 已配对的设备
+type PairedDevice struct {
+	ID        string
+	Name      string
+	TokenHash string // 设备凭据的 SHA-256
+}
+
+// pairing 服务端的配对状态
+type pairing struct {
+	enabled bool
+	pending map[string]chan bool
+	devices map[string]PairedDevice
+	lock    sync.Mutex
+}
+
+// VerificationCode 根据双方公钥计算 6 位校验码
+func VerificationCode(clientKey, serverKey string) string {
+	sum := sha256.Sum256([]byte(clientKey + ":" + serverKey))
+	return fmt.Sprintf("%06d", binary.BigEndian.Uint32(sum[:4])%1000000)
+}
+
+// commitKey 计算公钥承诺
+func commitKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// newPairKey 生成配对使用的临时密钥，返回私钥与十六进制公钥
+func newPairKey() (*ecdh.PrivateKey, string, error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, "", err
+	}
+	return priv, hex.EncodeToString(priv.PublicKey().Bytes()), nil
+}
+
+// pairCipher 根据己方私钥与对方公钥协商出加密设备凭据的密钥
+func pairCipher(priv *ecdh.PrivateKey, peerKey string) (cipher.AEAD, error) {
+	raw, err := hex.DecodeString(peerKey)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+	key := sha256.Sum256(append([]byte("ccsync-pair:"), shared...))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealToken 加密设备凭据，随机数放在密文前
+func sealToken(aead cipher.AEAD, token string) string {
+	nonce := make([]byte, aead.NonceSize())
+	rand.Read(nonce)
+	return hex.EncodeToString(aead.Seal(nonce, nonce, []byte(token), nil))
+}
+
+// openToken 解密 sealToken 加密的设备凭据
+func openToken(aead cipher.AEAD, sealed string) (string, error) {
+	data, err := hex.DecodeString(sealed)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", i18n.Errorf("pairing.invalidResponse")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", i18n.Errorf("pairing.invalidResponse")
+	}
+	return string(plain), nil
+}
+
+// HashToken 计算设备凭据的哈希，服务端只保存哈希
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// SetPairing 开启或关闭配对模式，关闭时拒绝所有待确认的请求
+func (s *Server) SetPairing(enabled bool) {
+	s.pairing.lock.Lock()
+	defer s.pairing.lock.Unlock()
+
+	s.pairing.enabled = enabled
+	if !enabled {
+		for id, ch := range s.pairing.pending {
+			ch <- false
+			delete(s.pairing.pending, id)
+		}
+	}
+}
+
+// IsPairing 检查是否处于配对模式
+func (s *Server) IsPairing() bool {
+	s.pairing.lock.Lock()
+	defer s.pairing.lock.Unlock()
+	return s.pairing.enabled
+}
+
+// RespondPairing 批准或拒绝配对请求
+func (s *Server) RespondPairing(id string, approve bool) error {
+	s.pairing.lock.Lock()
+	defer s.pairing.lock.Unlock()
+
+	ch, ok := s.pairing.pending[id]
+	if !ok {
+		return i18n.Errorf("pairing.requestNotFound")
+	}
+	ch <- approve
+	delete(s.pairing.pending, id)
+	return nil
+}
+
+// SetDevices 设置已配对的设备，并断开已被移除设备的连接
+func (s *Server) SetDevices(devices []PairedDevice) {
+	s.pairing.lock.Lock()
+	s.pairing.devices = make(map[string]PairedDevice, len(devices))
+	for _, d := range devices {
+		s.pairing.devices[d.ID] = d
+	}
+	s.pairing.lock.Unlock()
+
+	s.clientsLock.Lock()
+	for conn, p := range s.clients {
+		if p.deviceID != "" && !s.deviceKnown(p.deviceID) {
+			s.logger().Info("pairing.revoked", "device", p.deviceID)
+			conn.Close()
+		}
+	}
+	s.clientsLock.Unlock()
+}
+
+func (s *Server) deviceKnown(id string) bool {
+	s.pairing.lock.Lock()
+	defer s.pairing.lock.Unlock()
+	_, ok := s.pairing.devices[id]
+	return ok
+}
+
+// authorizeDevice 校验设备凭据
+func (s *Server) authorizeDevice(id, token string) bool {
+	s.pairing.lock.Lock()
+	d, ok := s.pairing.devices[id]
+	s.pairing.lock.Unlock()
+
+	return ok && subtle.ConstantTimeCompare([]byte(HashToken(token)), []byte(d.TokenHash)) == 1
+}
+
+// hasDevices 检查是否存在已配对设备
+func (s *Server) hasDevices() bool {
+	s.pairing.lock.Lock()
+	defer s.pairing.lock.Unlock()
+	return len(s.pairing.devices) > 0
+}
+
+// handlePairing 处理 /pair 上的配对请求
+func (s *Server) handlePairing(w http.ResponseWriter, r *http.Request) {
+	if !s.IsPairing() {
+		http.Error(w, "pairing disabled", http.StatusForbidden)
+		return
+	}
+	if code, reason := s.admitPairing(r); code != 0 {
+		s.logger().Warn("server.connRejected", "addr", r.RemoteAddr, "reason", reason)
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	conn, err := s.upgrader().Upgrade(w, r, nil)
+	if err != nil {
+		s.logger().Warn("server.upgradeFailed", "err", err)
+		return
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	var req pairMessage
+	if err := conn.ReadJSON(&req); err != nil || req.Type != TypePairRequest || req.DeviceID == "" || req.Commit == "" {
+		return
+	}
+
+	priv, serverKey, err := newPairKey()
+	if err != nil {
+		return
+	}
+	if err := conn.WriteJSON(pairMessage{Type: TypePairChallenge, Key: serverKey}); err != nil {
+		return
+	}
+
+	// 客户端公开的公钥必须与之前的承诺一致
+	var reveal pairMessage
+	if err := conn.ReadJSON(&reveal); err != nil || reveal.Type != TypePairKey ||
+		subtle.ConstantTimeCompare([]byte(commitKey(reveal.Key)), []byte(req.Commit)) != 1 {
+		return
+	}
+	aead, err := pairCipher(priv, reveal.Key)
+	if err != nil {
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	pr := PairRequest{
+		ID:         randomHex(8),
+		DeviceID:   req.DeviceID,
+		DeviceName: req.DeviceName,
+		Addr:       r.RemoteAddr,
+		Code:       VerificationCode(reveal.Key, serverKey),
+	}
+
+	decision := make(chan bool, 1)
+	s.pairing.lock.Lock()
+	if !s.pairing.enabled {
+		s.pairing.lock.Unlock()
+		return
+	}
+	if s.pairing.pending == nil {
+		s.pairing.pending = make(map[string]chan bool)
+	}
+	s.pairing.pending[pr.ID] = decision
+	s.pairing.lock.Unlock()
+
+	s.logger().Info("pairing.request", "device", pr.DeviceName, "addr", pr.Addr)
+	if s.OnPairRequest != nil {
+		s.OnPairRequest(pr)
+	}
+
+	approved := false
+	select {
+	case approved = <-decision:
+	case <-time.After(pairingTimeout):
+		s.pairing.lock.Lock()
+		delete(s.pairing.pending, pr.ID)
+		s.pairing.lock.Unlock()
+		s.logger().Info("pairing.requestExpired", "device", pr.DeviceName)
+	}
+
+	result := pairMessage{Type: TypePairResult, Approved: approved}
+	if approved {
+		token := randomHex(32)
+		result.Token = sealToken(aead, token)
+		device := PairedDevice{ID: pr.DeviceID, Name: pr.DeviceName, TokenHash: HashToken(token)}
+
+		s.pairing.lock.Lock()
+		if s.pairing.devices == nil {
+			s.pairing.devices = make(map[string]PairedDevice)
+		}
+		s.pairing.devices[device.ID] = device
+		s.pairing.lock.Unlock()
+
+		s.logger().Info("pairing.devicePaired", "device", pr.DeviceName)
+		if s.OnPaired != nil {
+			s.OnPaired(device)
+		}
+	} else {
+		s.logger().Info("pairing.rejected", "device", pr.DeviceName)
+	}
+
+	conn.WriteJSON(result)
+}
+
+// Pair 向服务端发起配对请求，返回设备凭据。
+// onCode 在收到服务端响应后被调用，应将校验码显示给用户与服务端核对。
+func Pair(ep Endpoint, deviceID, deviceName string, onCode func(code string)) (string, error) {
+	conn, resp, err := ep.dialer().Dial(ep.urlFor("/pair", nil), nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusForbidden {
+			return "", i18n.Errorf("pairing.notEnabled")
+		}
+		return "", err
+	}
+	defer conn.Close()
+
+	priv, clientKey, err := newPairKey()
+	if err != nil {
+		return "", err
+	}
+	request := pairMessage{Type: TypePairRequest, DeviceID: deviceID, DeviceName: deviceName, Commit: commitKey(clientKey)}
+	if err := conn.WriteJSON(request); err != nil {
+		return "", err
+	}
+
+	var challenge pairMessage
+	if err := conn.ReadJSON(&challenge); err != nil {
+		return "", err
+	}
+	if challenge.Type != TypePairChallenge || challenge.Key == "" {
+		return "", i18n.Errorf("pairing.invalidResponse")
+	}
+	aead, err := pairCipher(priv, challenge.Key)
+	if err != nil {
+		return "", i18n.Errorf("pairing.invalidResponse")
+	}
+	if err := conn.WriteJSON(pairMessage{Type: TypePairKey, Key: clientKey}); err != nil {
+		return "", err
+	}
+	if onCode != nil {
+		onCode(VerificationCode(clientKey, challenge.Key))
+	}
+
+	conn.SetReadDeadline(time.Now().Add(pairingTimeout + 10*time.Second))
+	var result pairMessage
+	if err := conn.ReadJSON(&result); err != nil {
+		if websocket.IsUnexpectedCloseError(err) {
+			return "", i18n.Errorf("pairing.closed")
+		}
+		return "", err
+	}
+	if !result.Approved || result.Token == "" {
+		return "", i18n.Errorf("pairing.rejected")
+	}
+	return openToken(aead, result.Token)
+}
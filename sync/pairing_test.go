@@ -0,0 +1,96 @@
+package sync
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newPairingServer(t *testing.T, onRequest func(s *Server, req PairRequest)) (*Server, string) {
+	t.Helper()
+	s := NewServer()
+	s.SetPairing(true)
+	s.SetHandlers(ServerHandlers{OnPairRequest: func(req PairRequest) { onRequest(s, req) }})
+
+	ts := httptest.NewServer(http.HandlerFunc(s.handlePairing))
+	t.Cleanup(ts.Close)
+	return s, strings.TrimPrefix(ts.URL, "http://")
+}
+
+func TestPair(t *testing.T) {
+	codes := make(chan string, 1)
+	s, addr := newPairingServer(t, func(s *Server, req PairRequest) {
+		codes <- req.Code
+		s.RespondPairing(req.ID, true)
+	})
+
+	var clientCode string
+	token, err := Pair(Endpoint{Address: addr}, "dev-1", "phone", func(code string) { clientCode = code })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serverCode := <-codes; len(clientCode) != 6 || clientCode != serverCode {
+		t.Errorf("codes = %q, %q, want equal", clientCode, serverCode)
+	}
+	if !s.authorizeDevice("dev-1", token) {
+		t.Error("paired token not accepted")
+	}
+}
+
+func TestPairRejectsBrokenCommitment(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	_, addr := newPairingServer(t, func(s *Server, req PairRequest) { requested <- struct{}{} })
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/pair", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, committed, _ := newPairKey()
+	_, revealed, _ := newPairKey()
+	conn.WriteJSON(pairMessage{Type: TypePairRequest, DeviceID: "dev-1", Commit: commitKey(committed)})
+	var challenge pairMessage
+	if err := conn.ReadJSON(&challenge); err != nil || challenge.Key == "" {
+		t.Fatalf("challenge = %+v, %v", challenge, err)
+	}
+	// 看到服务端公钥后换用另一个公钥
+	conn.WriteJSON(pairMessage{Type: TypePairKey, Key: revealed})
+
+	select {
+	case <-requested:
+		t.Fatal("request with a broken commitment reached the user")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSealToken(t *testing.T) {
+	a, aKey, _ := newPairKey()
+	b, bKey, _ := newPairKey()
+	sealer, err := pairCipher(a, bKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opener, err := pairCipher(b, aKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed := sealToken(sealer, "secret-token")
+	if strings.Contains(sealed, "secret-token") {
+		t.Fatal("token sent in clear")
+	}
+	if got, err := openToken(opener, sealed); err != nil || got != "secret-token" {
+		t.Fatalf("openToken = %q, %v", got, err)
+	}
+
+	_, other, _ := newPairKey()
+	eavesdropper, _ := pairCipher(a, other)
+	if _, err := openToken(eavesdropper, sealed); err == nil {
+		t.Error("token opened with a different key")
+	}
+}
@@ -0,0 +1,56 @@
+package sync
+
+import "sync"
+
+// DefaultSeenCacheSize 默认记录的消息 ID 数量
+const DefaultSeenCacheSize = 1024
+
+// SeenCache 记录最近处理过的消息 ID，用于防止消息回环。
+// 容量固定，超出后淘汰最早的记录。
+type SeenCache struct {
+	ids   map[string]struct{}
+	order []string
+	next  int
+	size  int
+	lock  sync.Mutex
+}
+
+// NewSeenCache 创建容量为 size 的消息 ID 缓存
+func NewSeenCache(size int) *SeenCache {
+	if size <= 0 {
+		size = DefaultSeenCacheSize
+	}
+	return &SeenCache{
+		ids:   make(map[string]struct{}, size),
+		order: make([]string, 0, size),
+		size:  size,
+	}
+}
+
+// Add 记录消息 ID，若该 ID 之前已出现过则返回 false
+func (c *SeenCache) Add(id string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.ids[id]; ok {
+		return false
+	}
+
+	if len(c.order) < c.size {
+		c.order = append(c.order, id)
+	} else {
+		delete(c.ids, c.order[c.next])
+		c.order[c.next] = id
+		c.next = (c.next + 1) % c.size
+	}
+	c.ids[id] = struct{}{}
+	return true
+}
+
+// Contains 检查消息 ID 是否已出现过
+func (c *SeenCache) Contains(id string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, ok := c.ids[id]
+	return ok
+}
@@ -0,0 +1,66 @@
+package sync
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSeenCacheDuplicate(t *testing.T) {
+	c := NewSeenCache(4)
+	if !c.Add("a") {
+		t.Fatal("first Add(a) = false")
+	}
+	if c.Add("a") {
+		t.Fatal("second Add(a) = true")
+	}
+	if !c.Contains("a") || c.Contains("b") {
+		t.Fatal("Contains mismatch")
+	}
+}
+
+func TestSeenCacheEviction(t *testing.T) {
+	c := NewSeenCache(3)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		c.Add(id)
+	}
+	// 超出容量后最早的 a 被淘汰，可以再次加入
+	if c.Contains("a") {
+		t.Fatal("a not evicted")
+	}
+	for _, id := range []string{"b", "c", "d"} {
+		if !c.Contains(id) {
+			t.Fatalf("%s evicted too early", id)
+		}
+	}
+	if !c.Add("a") {
+		t.Fatal("Add(a) after eviction = false")
+	}
+	if c.Contains("b") {
+		t.Fatal("b not evicted after re-adding a")
+	}
+}
+
+func TestSeenCacheWrap(t *testing.T) {
+	const size = 5
+	c := NewSeenCache(size)
+	for i := 0; i < size*3; i++ {
+		if !c.Add(strconv.Itoa(i)) {
+			t.Fatalf("Add(%d) = false", i)
+		}
+		if len(c.ids) > size {
+			t.Fatalf("cache holds %d ids, capacity %d", len(c.ids), size)
+		}
+	}
+	// 只保留最近的 size 个
+	for i := 0; i < size*3; i++ {
+		if got, want := c.Contains(strconv.Itoa(i)), i >= size*2; got != want {
+			t.Errorf("Contains(%d) = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestSeenCacheDefaultSize(t *testing.T) {
+	if c := NewSeenCache(0); c.size != DefaultSeenCacheSize {
+		t.Fatalf("size = %d, want %d", c.size, DefaultSeenCacheSize)
+	}
+}
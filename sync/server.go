@@ -1,44 +1,105 @@
 package sync
 
 import (
+	"crypto/subtle"
 	"encoding/json"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
+	"time"
+
+	"ccsync-net/i18n"
+	"ccsync-net/logging"
 
 	"github.com/gorilla/websocket"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // 允许所有来源
-	},
+// peer 已连接的客户端
+type peer struct {
+	conn        *websocket.Conn
+	channel     string // 所在频道，空字符串为默认频道（与本机同步）
+	addr        string
+	deviceID    string  // 通过设备凭据认证时的设备 ID
+	limiter     *bucket // 消息限流，nil 表示不限制
+	connectedAt time.Time
+	stats       *serverMetrics
+	writeLock   sync.Mutex
+}
+
+// write 发送消息，同一连接不允许并发写入
+func (p *peer) write(data []byte) error {
+	p.writeLock.Lock()
+	defer p.writeLock.Unlock()
+	if err := p.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		p.stats.writeErrors.Add(1)
+		return err
+	}
+	p.stats.bytesOut.Add(uint64(len(data)))
+	return nil
 }
 
 // Server WebSocket 服务端
 type Server struct {
 	port        int
-	clients     map[*websocket.Conn]bool
+	bind        []string
+	secret      string
+	deviceID    string // 本机设备 ID，用于节点间认证
+	requirePair bool
+	apiToken    string
+	listenAddrs []string
+	startedAt   time.Time
+	clients     map[*websocket.Conn]*peer
 	clientsLock sync.RWMutex
 	server      *http.Server
 	running     bool
 	runningLock sync.RWMutex
+	seen        *SeenCache
+	policy      *accessPolicy
+	connLimiter *ipLimiter
+	rejects     rejectCounters
+	metrics     *serverMetrics
+	pairing     pairing
+	keepLast    bool
+	lastClips   map[string]*Message // 各频道最近一条内容
+	lastLock    sync.RWMutex
+
+	ServerHandlers
+}
 
-	// 回调函数
-	OnClipboardReceived func(content string)
-	OnClientConnected   func(count int)
+// ServerHandlers 服务端的回调函数
+type ServerHandlers struct {
+	OnClipboardReceived  func(msg *Message)
+	OnClientConnected    func(count int)
+	OnPeerConnected      func(p PeerInfo)
 	OnClientDisconnected func(count int)
-	OnLog               func(msg string)
+	OnError              func(err error) // 运行中监听失败，服务端已停止
+	OnPairRequest        func(req PairRequest)
+	OnPaired             func(device PairedDevice)
+	OnMeshPeer           func(id, addr string) // 设备 ID 较大的节点通过认证后告知自己的地址
+}
+
+// SetHandlers 设置回调函数，应在 Start 之前调用
+func (s *Server) SetHandlers(h ServerHandlers) {
+	s.ServerHandlers = h
 }
 
 // NewServer 创建服务端实例
 func NewServer() *Server {
 	return &Server{
-		clients: make(map[*websocket.Conn]bool),
+		clients:     make(map[*websocket.Conn]*peer),
+		policy:      &accessPolicy{},
+		connLimiter: newIPLimiter(),
+		seen:        NewSeenCache(DefaultSeenCacheSize),
+		lastClips:   make(map[string]*Message),
+		metrics:     newServerMetrics(),
 	}
 }
 
-// Start 启动服务端
+// Start 启动服务端，监听地址由 SetBindAddresses 指定。
+// 任一地址绑定失败时返回错误，不会启动。
 func (s *Server) Start(port int) error {
 	s.runningLock.Lock()
 	if s.running {
@@ -46,41 +107,90 @@ func (s *Server) Start(port int) error {
 		return nil
 	}
 	s.port = port
+	bind := s.bind
 	s.runningLock.Unlock()
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/ws", s.handleConnection)
+	addrs, err := ResolveBindAddresses(bind, port)
+	if err != nil {
+		return err
+	}
 
-	s.server = &http.Server{
-		Addr:    ":" + string(rune(port)),
-		Handler: mux,
+	var listeners []net.Listener
+	for _, addr := range addrs {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			s.logger().Error("server.listenFailed", "addr", addr, "err", err)
+			return err
+		}
+		listeners = append(listeners, l)
 	}
 
-	// 修正端口格式
-	addr := "0.0.0.0:" + itoa(port)
-	s.server = &http.Server{
-		Addr:    addr,
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", s.handleConnection)
+	mux.HandleFunc("/pair", s.handlePairing)
+	mux.HandleFunc("/api/clip", s.handleAPI(s.apiPostClip))
+	mux.HandleFunc("/api/clip/latest", s.handleAPI(s.apiLatestClip))
+	mux.HandleFunc("/api/peers", s.handleAPI(s.apiPeers))
+	mux.HandleFunc("/api/status", s.handleAPI(s.apiStatus))
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/", s.handleWeb)
+
+	srv := &http.Server{
 		Handler: mux,
 	}
 
 	s.runningLock.Lock()
+	s.server = srv
 	s.running = true
+	s.startedAt = time.Now()
+	s.listenAddrs = s.listenAddrs[:0]
+	for _, l := range listeners {
+		s.listenAddrs = append(s.listenAddrs, l.Addr().String())
+	}
 	s.runningLock.Unlock()
 
-	s.log("服务端启动于 " + addr)
+	for _, l := range listeners {
+		s.logger().Info("server.started", "addr", l.Addr().String())
 
-	go func() {
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			s.log("服务端错误: " + err.Error())
-			s.runningLock.Lock()
-			s.running = false
-			s.runningLock.Unlock()
-		}
-	}()
+		go func(l net.Listener) {
+			if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+				s.fail(srv, err)
+			}
+		}(l)
+	}
 
 	return nil
 }
 
+// fail 处理运行中的监听错误：停止整个服务端并通知
+func (s *Server) fail(srv *http.Server, err error) {
+	s.runningLock.RLock()
+	current := s.server == srv && s.running
+	s.runningLock.RUnlock()
+
+	// 已被停止或重新启动，忽略旧实例的错误
+	if !current {
+		return
+	}
+
+	s.logger().Error("server.error", "err", err)
+	s.Stop()
+	if s.OnError != nil {
+		s.OnError(err)
+	}
+}
+
+// SetBindAddresses 设置监听的地址或网卡名称，为空时监听所有地址。
+// 下次 Start 时生效。
+func (s *Server) SetBindAddresses(entries []string) {
+	s.runningLock.Lock()
+	s.bind = append([]string(nil), entries...)
+	s.runningLock.Unlock()
+}
+
 // Stop 停止服务端
 func (s *Server) Stop() error {
 	s.runningLock.Lock()
@@ -97,17 +207,218 @@ func (s *Server) Stop() error {
 	for conn := range s.clients {
 		conn.Close()
 	}
-	s.clients = make(map[*websocket.Conn]bool)
+	s.clients = make(map[*websocket.Conn]*peer)
 	s.clientsLock.Unlock()
 
 	if s.server != nil {
 		s.server.Close()
 	}
 
-	s.log("服务端已停止")
+	s.logger().Info("server.stopped")
 	return nil
 }
 
+// SetSecret 设置客户端认证密钥，为空时不校验
+func (s *Server) SetSecret(secret string) {
+	s.runningLock.Lock()
+	s.secret = secret
+	s.runningLock.Unlock()
+}
+
+// SetDeviceID 设置本机设备 ID，未设置时不接受节点间的挑战应答连接
+func (s *Server) SetDeviceID(id string) {
+	s.runningLock.Lock()
+	s.deviceID = id
+	s.runningLock.Unlock()
+}
+
+// SetRequirePairing 设置是否只允许已配对设备连接。
+// 关闭时只有设置了密钥才校验，已配对设备也可以用各自的凭据连接。
+func (s *Server) SetRequirePairing(require bool) {
+	s.runningLock.Lock()
+	s.requirePair = require
+	s.runningLock.Unlock()
+}
+
+// SetLimits 设置访问控制与限流，立即对新连接生效
+func (s *Server) SetLimits(l Limits) error {
+	policy, err := newAccessPolicy(l)
+	if err != nil {
+		return err
+	}
+	s.runningLock.Lock()
+	s.policy = policy
+	s.runningLock.Unlock()
+	return nil
+}
+
+// RejectStats 返回被拒绝的连接与消息计数
+func (s *Server) RejectStats() RejectStats {
+	return s.rejects.snapshot()
+}
+
+// admit 检查来源 IP 是否允许建立连接，拒绝时返回 HTTP 状态码
+func (s *Server) admit(r *http.Request) (int, string) {
+	s.runningLock.RLock()
+	policy := s.policy
+	s.runningLock.RUnlock()
+
+	if !s.originAllowed(r) {
+		s.rejects.denied.Add(1)
+		return http.StatusForbidden, i18n.T("reject.origin")
+	}
+
+	if !s.permitted(r) {
+		s.rejects.denied.Add(1)
+		return http.StatusForbidden, i18n.T("reject.denied")
+	}
+
+	if policy.maxClients > 0 && s.GetClientCount() >= policy.maxClients {
+		s.rejects.tooManyClients.Add(1)
+		return http.StatusServiceUnavailable, i18n.T("reject.tooManyClients")
+	}
+
+	if !s.allowConn(r) {
+		return http.StatusTooManyRequests, i18n.T("reject.connRate")
+	}
+
+	// 节点间连接在升级后校验证明
+	if !s.authorized(r) && !s.meshRequested(r) {
+		s.rejects.unauthorized.Add(1)
+		return http.StatusUnauthorized, i18n.T("reject.unauthorized")
+	}
+
+	return 0, ""
+}
+
+// admitPairing 检查配对请求，与 admit 相同的来源与频率限制，但不限制连接数、不要求认证
+func (s *Server) admitPairing(r *http.Request) (int, string) {
+	if !s.originAllowed(r) {
+		s.rejects.denied.Add(1)
+		return http.StatusForbidden, i18n.T("reject.origin")
+	}
+	if !s.permitted(r) {
+		s.rejects.denied.Add(1)
+		return http.StatusForbidden, i18n.T("reject.denied")
+	}
+	if !s.allowConn(r) {
+		return http.StatusTooManyRequests, i18n.T("reject.connRate")
+	}
+	return 0, ""
+}
+
+// upgrader 升级 WebSocket 连接，来源检查同 originAllowed
+func (s *Server) upgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{CheckOrigin: s.originAllowed}
+}
+
+// originAllowed 检查浏览器请求的来源，防止任意网页连接本机服务端。
+// 非浏览器客户端不发送 Origin；浏览器请求只允许来自本服务端的网页 (与 Host 相同) 或监听地址。
+func (s *Server) originAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	s.runningLock.RLock()
+	addrs := append([]string(nil), s.listenAddrs...)
+	s.runningLock.RUnlock()
+	for _, addr := range addrs {
+		if host, _, err := net.SplitHostPort(addr); err == nil && strings.EqualFold(host, u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowConn 按连接频率限制检查来源 IP
+func (s *Server) allowConn(r *http.Request) bool {
+	s.runningLock.RLock()
+	rate := s.policy.connPerMinute
+	s.runningLock.RUnlock()
+
+	if rate > 0 && !s.connLimiter.allow(remoteHost(r), rate) {
+		s.rejects.connRateLimit.Add(1)
+		return false
+	}
+	return true
+}
+
+// authorized 校验请求携带的密钥或设备凭据。
+// 未设置密钥且未要求配对时不校验。
+func (s *Server) authorized(r *http.Request) bool {
+	s.runningLock.RLock()
+	secret, requirePair := s.secret, s.requirePair
+	s.runningLock.RUnlock()
+
+	if secret == "" && !requirePair {
+		return true
+	}
+
+	token := requestToken(r)
+	if token == "" {
+		return false
+	}
+
+	if secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+		return true
+	}
+	return s.authorizeDevice(requestDeviceID(r), token)
+}
+
+// requestToken 获取请求携带的密钥或设备凭据
+func requestToken(r *http.Request) string {
+	if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); token != "" {
+		return token
+	}
+	return r.URL.Query().Get("key")
+}
+
+// requestDeviceID 获取请求携带的设备 ID
+func requestDeviceID(r *http.Request) string {
+	if id := r.Header.Get(HeaderDeviceID); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("device")
+}
+
+// SetKeepLast 设置是否保存各频道最近一条内容，并在客户端连接时发送给它。
+// 敏感内容不会被保存。
+func (s *Server) SetKeepLast(keep bool) {
+	s.lastLock.Lock()
+	s.keepLast = keep
+	if !keep {
+		s.lastClips = make(map[string]*Message)
+	}
+	s.lastLock.Unlock()
+}
+
+// LastClip 返回频道内最近一条内容，未保存时返回 nil
+func (s *Server) LastClip(channel string) *Message {
+	s.lastLock.RLock()
+	defer s.lastLock.RUnlock()
+	return s.lastClips[channel]
+}
+
+func (s *Server) storeLast(channel string, msg *Message) {
+	// 启用 API 时同样需要保存，以便通过 /api/clip/latest 获取
+	api := s.apiEnabled()
+
+	s.lastLock.Lock()
+	if (s.keepLast || api) && !msg.Sensitive {
+		m := *msg
+		s.lastClips[channel] = &m
+	}
+	s.lastLock.Unlock()
+}
+
 // IsRunning 检查服务端是否运行中
 func (s *Server) IsRunning() bool {
 	s.runningLock.RLock()
@@ -122,20 +433,35 @@ func (s *Server) GetClientCount() int {
 	return len(s.clients)
 }
 
-// Broadcast 广播消息给所有客户端
+// Broadcast 广播消息给默认频道的所有客户端
 func (s *Server) Broadcast(msg *Message) {
+	if msg.ID != "" {
+		// 记录自身发出的消息，被转发回来时直接丢弃
+		s.seen.Add(msg.ID)
+	}
+
 	data, err := json.Marshal(msg)
 	if err != nil {
-		s.log("消息序列化失败: " + err.Error())
+		s.logger().Error("server.marshalFailed", "err", err)
 		return
 	}
+	if msg.Type == TypeClipboard {
+		s.storeLast("", msg)
+	}
 
 	s.clientsLock.RLock()
 	defer s.clientsLock.RUnlock()
 
-	for conn := range s.clients {
-		if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-			s.log("发送消息失败: " + err.Error())
+	for _, p := range s.clients {
+		if p.channel != "" {
+			continue
+		}
+		if err := p.write(data); err != nil {
+			s.logger().Warn("sync.sendFailed", "addr", p.addr, "err", err)
+			continue
+		}
+		if msg.Type == TypeClipboard {
+			s.metrics.broadcast.Add(1)
 		}
 	}
 }
@@ -147,21 +473,68 @@ func (s *Server) BroadcastClipboard(content, source string) {
 }
 
 func (s *Server) handleConnection(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	if code, reason := s.admit(r); code != 0 {
+		s.logger().Warn("server.connRejected", "addr", r.RemoteAddr, "reason", reason)
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	var header http.Header
+	var serverNonce string
+	mesh := s.meshRequested(r)
+	if mesh {
+		header, serverNonce = s.meshChallenge(r)
+	}
+
+	conn, err := s.upgrader().Upgrade(w, r, header)
 	if err != nil {
-		s.log("连接升级失败: " + err.Error())
+		s.logger().Warn("server.upgradeFailed", "err", err)
+		return
+	}
+	if mesh && !s.verifyMesh(conn, r, serverNonce) {
+		conn.Close()
 		return
 	}
 
+	self := &peer{
+		conn:        conn,
+		channel:     r.URL.Query().Get("channel"),
+		addr:        r.RemoteAddr,
+		connectedAt: time.Now(),
+		stats:       s.metrics,
+	}
+	if id := requestDeviceID(r); id != "" && s.authorizeDevice(id, requestToken(r)) {
+		self.deviceID = id
+	}
+	s.runningLock.RLock()
+	if rate := s.policy.msgPerSecond; rate > 0 {
+		self.limiter = newBucket(float64(rate), rate)
+	}
+	s.runningLock.RUnlock()
+
 	s.clientsLock.Lock()
-	s.clients[conn] = true
+	s.clients[conn] = self
 	count := len(s.clients)
 	s.clientsLock.Unlock()
+	s.metrics.connections.Add(1)
 
-	s.log("新客户端连接，当前连接数: " + itoa(count))
+	s.logger().Info("server.clientConnected", "addr", self.addr, "channel", self.channel, "clients", count)
 	if s.OnClientConnected != nil {
 		s.OnClientConnected(count)
 	}
+	if s.OnPeerConnected != nil {
+		s.OnPeerConnected(s.peerInfo(self))
+	}
+
+	// 发送该频道最近一条内容
+	s.lastLock.RLock()
+	keepLast := s.keepLast
+	s.lastLock.RUnlock()
+	if last := s.LastClip(self.channel); keepLast && last != nil {
+		if data, err := json.Marshal(last); err == nil {
+			self.write(data)
+		}
+	}
 
 	defer func() {
 		s.clientsLock.Lock()
@@ -170,7 +543,7 @@ func (s *Server) handleConnection(w http.ResponseWriter, r *http.Request) {
 		s.clientsLock.Unlock()
 		conn.Close()
 
-		s.log("客户端断开，当前连接数: " + itoa(count))
+		s.logger().Info("server.clientDisconnected", "addr", self.addr, "clients", count)
 		if s.OnClientDisconnected != nil {
 			s.OnClientDisconnected(count)
 		}
@@ -181,57 +554,69 @@ func (s *Server) handleConnection(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			break
 		}
+		s.metrics.bytesIn.Add(uint64(len(data)))
 
 		var msg Message
 		if err := json.Unmarshal(data, &msg); err != nil {
+			s.metrics.invalid.Add(1)
 			continue
 		}
 
 		switch msg.Type {
 		case TypeClipboard:
-			if s.OnClipboardReceived != nil {
-				s.OnClipboardReceived(msg.Content)
+			s.metrics.received.Add(1)
+			s.metrics.latency.observeLatency(&msg)
+			if self.limiter != nil && !self.limiter.allow() {
+				s.rejects.msgRateLimit.Add(1)
+				s.logger().Warn("server.rateLimited", "addr", self.addr)
+				continue
 			}
-			// 转发给其他客户端
-			s.clientsLock.RLock()
-			for c := range s.clients {
-				if c != conn {
-					c.WriteMessage(websocket.TextMessage, data)
+			if msg.ID == "" || msg.VerifiedDevice != self.deviceID {
+				// 兼容旧版本客户端：补充消息 ID；认证结果由本机填写后再转发
+				if msg.ID == "" {
+					msg.ID = NewMessageID()
+				}
+				msg.VerifiedDevice = self.deviceID
+				if data, err = json.Marshal(&msg); err != nil {
+					continue
 				}
 			}
-			s.clientsLock.RUnlock()
+			msg.AuthDevice = self.deviceID
+			s.publish(self.channel, &msg, data, conn)
 
 		case TypePing:
 			pong := NewPongMessage()
 			pongData, _ := json.Marshal(pong)
-			conn.WriteMessage(websocket.TextMessage, pongData)
+			self.write(pongData)
 		}
 	}
 }
 
-func (s *Server) log(msg string) {
-	log.Println("[Server]", msg)
-	if s.OnLog != nil {
-		s.OnLog(msg)
+// publish 将收到的剪贴板消息转发给同一频道中除 from 以外的客户端，
+// 默认频道的内容同时交给本机处理。已处理过的消息会被丢弃。
+func (s *Server) publish(channel string, msg *Message, data []byte, from *websocket.Conn) {
+	if !s.seen.Add(msg.ID) {
+		// 已处理过的消息，避免回环
+		s.metrics.duplicates.Add(1)
+		return
 	}
-}
 
-// 简单的 int 转 string
-func itoa(n int) string {
-	if n == 0 {
-		return "0"
-	}
-	var result string
-	negative := n < 0
-	if negative {
-		n = -n
-	}
-	for n > 0 {
-		result = string(rune('0'+n%10)) + result
-		n /= 10
+	s.storeLast(channel, msg)
+
+	// 仅默认频道的内容写入本机剪贴板
+	if channel == "" && s.OnClipboardReceived != nil {
+		s.OnClipboardReceived(msg)
 	}
-	if negative {
-		result = "-" + result
+	// 转发给同一频道的其他客户端
+	s.clientsLock.RLock()
+	for c, p := range s.clients {
+		if c != from && p.channel == channel && p.write(data) == nil {
+			s.metrics.relayed.Add(1)
+		}
 	}
-	return result
+	s.clientsLock.RUnlock()
+}
+
+func (s *Server) logger() *slog.Logger {
+	return logging.Component("server")
 }
@@ -0,0 +1,184 @@
+package sync
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	s := NewServer()
+	s.listenAddrs = []string{"192.168.1.5:8765", "[::1]:8765"}
+
+	tests := []struct {
+		host   string
+		origin string
+		want   bool
+	}{
+		{host: "localhost:8765", origin: "", want: true},
+		{host: "localhost:8765", origin: "http://localhost:8765", want: true},
+		{host: "LocalHost:8765", origin: "http://localhost:8765", want: true},
+		{host: "localhost:8765", origin: "http://localhost:9000", want: false},
+		{host: "localhost:8765", origin: "https://evil.example", want: false},
+		{host: "localhost:8765", origin: "null", want: false},
+		{host: "localhost:8765", origin: "http://192.168.1.5", want: true},
+		{host: "localhost:8765", origin: "http://[::1]:3000", want: true},
+		{host: "localhost:8765", origin: "http://192.168.1.6:8765", want: false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "http://"+tt.host+"/ws", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if got := s.originAllowed(r); got != tt.want {
+			t.Errorf("host %s origin %q: originAllowed = %v, want %v", tt.host, tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestAdmitRejectsForeignOrigin(t *testing.T) {
+	s := NewServer()
+	r := httptest.NewRequest("GET", "http://localhost:8765/ws", nil)
+	r.Header.Set("Origin", "https://evil.example")
+
+	if code, _ := s.admit(r); code != http.StatusForbidden {
+		t.Errorf("admit = %d, want 403", code)
+	}
+	if code, _ := s.admitPairing(r); code != http.StatusForbidden {
+		t.Errorf("admitPairing = %d, want 403", code)
+	}
+}
+
+func TestMetricsAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		apiToken string
+		auth     string
+		query    string
+		want     int
+	}{
+		{name: "open", want: http.StatusOK},
+		{name: "secret missing", secret: "s", want: http.StatusUnauthorized},
+		{name: "secret bearer", secret: "s", auth: "Bearer s", want: http.StatusOK},
+		{name: "secret query", secret: "s", query: "?key=s", want: http.StatusOK},
+		{name: "api token missing", secret: "s", apiToken: "t", auth: "Bearer s", want: http.StatusUnauthorized},
+		{name: "api token", apiToken: "t", auth: "Bearer t", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		s := NewServer()
+		s.SetSecret(tt.secret)
+		s.SetAPIToken(tt.apiToken)
+		r := httptest.NewRequest("GET", "http://localhost:8765/metrics"+tt.query, nil)
+		if tt.auth != "" {
+			r.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		s.handleMetrics(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+
+	s := NewServer()
+	if err := s.SetLimits(Limits{Deny: []string{"192.0.2.0/24"}}); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	s.handleMetrics(w, httptest.NewRequest("GET", "http://localhost:8765/metrics", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("denied: status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestAuthDevice(t *testing.T) {
+	s := NewServer()
+	s.SetDevices([]PairedDevice{{ID: "dev-1", Name: "phone", TokenHash: HashToken("token-1")}})
+	received := make(chan *Message, 1)
+	s.SetHandlers(ServerHandlers{OnClipboardReceived: func(msg *Message) { received <- msg }})
+
+	ts := httptest.NewServer(http.HandlerFunc(s.handleConnection))
+	defer ts.Close()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	tests := []struct {
+		name   string
+		device string
+		token  string
+		want   string
+	}{
+		{name: "valid token", device: "dev-1", token: "token-1", want: "dev-1"},
+		{name: "wrong token", device: "dev-1", token: "token-2", want: ""},
+		{name: "no token", device: "dev-1", want: ""},
+		{name: "unknown device", device: "dev-2", token: "token-1", want: ""},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		header.Set(HeaderDeviceID, tt.device)
+		if tt.token != "" {
+			header.Set("Authorization", "Bearer "+tt.token)
+		}
+		conn, _, err := websocket.DefaultDialer.Dial(url, header)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		msg := NewClipboardMessage("x", "test")
+		msg.Origin = "dev-1"         // 自报的来源不影响认证结果
+		msg.VerifiedDevice = "dev-1" // 由服务端覆盖
+		if err := conn.WriteJSON(msg); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case got := <-received:
+			if got.AuthDevice != tt.want || got.VerifiedDevice != tt.want {
+				t.Errorf("%s: AuthDevice, VerifiedDevice = %q, %q, want %q", tt.name, got.AuthDevice, got.VerifiedDevice, tt.want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: message not received", tt.name)
+		}
+		conn.Close()
+	}
+}
+
+func TestClientMetricsLabelled(t *testing.T) {
+	upstream := NewClient()
+	peer := NewClient()
+	peer.component = "mesh"
+	peer.endpoints = []Endpoint{{Name: "peer-1"}}
+	for _, c := range []*Client{upstream, peer} {
+		registerClient(c)
+		defer unregisterClient(c)
+	}
+	upstream.stats.sent.Add(2)
+	peer.stats.sent.Add(1)
+	peer.stats.latency.observe(0.02)
+
+	var buf strings.Builder
+	NewServer().writeMetrics(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		`ccsync_client_messages_sent_total{client="client"} 2`,
+		`ccsync_client_messages_sent_total{client="mesh/peer-1"} 1`,
+		`ccsync_client_message_latency_seconds_bucket{client="mesh/peer-1",le="0.025"} 1`,
+		`ccsync_client_message_latency_seconds_count{client="client"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+	if n := strings.Count(out, "# TYPE ccsync_client_messages_sent_total"); n != 1 {
+		t.Errorf("TYPE lines = %d, want 1", n)
+	}
+
+	unregisterClient(peer)
+	buf.Reset()
+	NewServer().writeMetrics(&buf)
+	if strings.Contains(buf.String(), "mesh/peer-1") {
+		t.Error("disconnected client still reported")
+	}
+}
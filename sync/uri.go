@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"ccsync-net/i18n"
+)
+
+// URIScheme 连接 URI 的协议名
+const URIScheme = "ccsync"
+
+// URI 返回包含地址、认证密钥和证书指纹的连接 URI，
+// 形如 ccsync://host:port?key=...&fp=...，可生成二维码供其他设备导入
+func (e Endpoint) URI() string {
+	addr := e.Address
+	if i := strings.Index(addr, "://"); i >= 0 {
+		addr = addr[i+3:]
+	}
+	addr = strings.TrimSuffix(addr, "/")
+
+	q := url.Values{}
+	if e.Secret != "" {
+		q.Set("key", e.Secret)
+	}
+	if e.TLSPin != "" {
+		q.Set("fp", normalizeFingerprint(e.TLSPin))
+	}
+	if e.Channel != "" {
+		q.Set("channel", e.Channel)
+	}
+	if e.Name != "" {
+		q.Set("name", e.Name)
+	}
+
+	u := url.URL{Scheme: URIScheme, Host: addr, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// ParseURI 解析连接 URI
+func ParseURI(s string) (Endpoint, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return Endpoint{}, err
+	}
+	if u.Scheme != URIScheme {
+		return Endpoint{}, i18n.Errorf("uri.invalidScheme", "scheme", URIScheme)
+	}
+	if _, _, err := net.SplitHostPort(u.Host); err != nil {
+		return Endpoint{}, i18n.Errorf("uri.missingHost")
+	}
+
+	q := u.Query()
+	return Endpoint{
+		Name:    q.Get("name"),
+		Address: u.Host,
+		Secret:  q.Get("key"),
+		TLSPin:  q.Get("fp"),
+		Channel: q.Get("channel"),
+	}, nil
+}
@@ -0,0 +1,29 @@
+package sync
+
+import (
+	_ "embed"
+	"net/http"
+
+	"ccsync-net/i18n"
+)
+
+//go:embed web/index.html
+var webPage []byte
+
+// handleWeb 提供浏览器客户端页面。页面本身不含任何剪贴板内容，
+// 内容通过 /ws 获取，与原生客户端使用相同的认证。
+func (s *Server) handleWeb(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if !s.permitted(r) {
+		http.Error(w, i18n.T("reject.denied"), http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(webPage)
+}
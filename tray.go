@@ -0,0 +1,352 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"ccsync-net/clipboard"
+	"ccsync-net/config"
+	"ccsync-net/hotkey"
+	"ccsync-net/i18n"
+	"ccsync-net/logging"
+	"ccsync-net/service"
+
+	wailsRun "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/energye/systray"
+)
+
+// trayPreviewLength 最近内容菜单项显示的最大字符数
+const trayPreviewLength = 40
+
+// traySyncModes 托盘中可切换的同步方向
+var traySyncModes = []string{
+	config.SyncBidirectional,
+	config.SyncSendOnly,
+	config.SyncReceiveOnly,
+	config.SyncDisabled,
+	config.SyncManual,
+}
+
+// trayMenu 托盘菜单项，标题与状态由 updateTray 统一刷新
+type trayMenu struct {
+	icons map[trayState][]byte
+	icon  trayState
+
+	status  *systray.MenuItem // 服务端或 mesh 状态，不可点击
+	detail  *systray.MenuItem // 客户端状态，不可点击
+	show    *systray.MenuItem
+	server  *systray.MenuItem // 启动/停止服务端或 mesh
+	connect *systray.MenuItem // 连接/断开服务端
+	pause   *systray.MenuItem
+	pause15 *systray.MenuItem
+	push    *systray.MenuItem // 手动发送当前剪贴板
+	pull    *systray.MenuItem // 手动写入最近收到的内容
+
+	syncMode  *systray.MenuItem
+	syncModes map[string]*systray.MenuItem
+
+	recent      *systray.MenuItem
+	recentEmpty *systray.MenuItem
+	recentItems []*systray.MenuItem
+
+	pending      *systray.MenuItem // 等待确认的内容，没有时隐藏
+	pendingItems []*systray.MenuItem
+	rejectAll    *systray.MenuItem
+
+	quit *systray.MenuItem
+}
+
+func (a *App) onTrayReady() {
+	systray.SetIcon(iconData)
+	systray.SetTitle("CCSync Net")
+	systray.SetTooltip("CCSync Net")
+
+	t := &trayMenu{
+		icons:     trayIcons(),
+		icon:      trayIdle,
+		syncModes: make(map[string]*systray.MenuItem),
+	}
+
+	t.status = systray.AddMenuItem("", "")
+	t.status.Disable()
+	t.detail = systray.AddMenuItem("", "")
+	t.detail.Disable()
+	systray.AddSeparator()
+	t.show = systray.AddMenuItem("", "")
+	t.server = systray.AddMenuItem("", "")
+	t.connect = systray.AddMenuItem("", "")
+	systray.AddSeparator()
+	t.pause = systray.AddMenuItemCheckbox("", "", false)
+	t.pause15 = systray.AddMenuItem("", "")
+	t.push = systray.AddMenuItem("", "")
+	t.pull = systray.AddMenuItem("", "")
+	t.syncMode = systray.AddMenuItem("", "")
+	for _, mode := range traySyncModes {
+		// 仅手动模式依赖全局热键
+		if mode == config.SyncManual && hotkey.Supported() != nil {
+			continue
+		}
+		item := t.syncMode.AddSubMenuItemCheckbox("", "", false)
+		item.Click(func() {
+			a.trayAction(a.svc.UpdateConfig(func(c *config.Config) {
+				c.SyncMode = mode
+			}))
+		})
+		t.syncModes[mode] = item
+	}
+	t.recent = systray.AddMenuItem("", "")
+	t.recentEmpty = t.recent.AddSubMenuItem("", "")
+	t.recentEmpty.Disable()
+	for i := 0; i < service.RecentClipsSize; i++ {
+		item := t.recent.AddSubMenuItem("", "")
+		item.Hide()
+		item.Click(func() {
+			a.trayAction(a.svc.CopyRecent(i))
+		})
+		t.recentItems = append(t.recentItems, item)
+	}
+	t.pending = systray.AddMenuItem("", "")
+	for i := 0; i < service.PendingClipsSize; i++ {
+		item := t.pending.AddSubMenuItem("", "")
+		item.Hide()
+		item.Click(func() {
+			// 按点击时的列表接受，与菜单显示的顺序一致
+			if clips := a.svc.PendingClips(); i < len(clips) {
+				a.trayAction(a.svc.AcceptClip(clips[i].ID, false))
+			}
+		})
+		t.pendingItems = append(t.pendingItems, item)
+	}
+	t.rejectAll = t.pending.AddSubMenuItem("", "")
+	t.rejectAll.Click(a.svc.RejectAllClips)
+	systray.AddSeparator()
+	t.quit = systray.AddMenuItem("", "")
+
+	t.show.Click(func() {
+		wailsRun.WindowShow(a.ctx)
+	})
+
+	t.server.Click(func() {
+		a.trayAction(a.svc.ToggleServer())
+	})
+
+	t.connect.Click(func() {
+		if a.svc.Status().ClientActive {
+			a.svc.Disconnect()
+		} else {
+			a.trayAction(a.svc.ConnectConfigured())
+		}
+	})
+
+	t.pause.Click(a.svc.TogglePause)
+
+	t.pause15.Click(func() {
+		a.svc.SetPaused(true, true, 15*time.Minute)
+	})
+
+	t.push.Click(func() {
+		a.trayAction(a.svc.PushClipboard())
+	})
+
+	t.pull.Click(func() {
+		a.trayAction(a.svc.ApplyLatest())
+	})
+
+	t.quit.Click(func() {
+		a.isQuitting.Store(true)
+		systray.Quit()
+		wailsRun.Quit(a.ctx)
+	})
+
+	a.updateTray(t)
+	go a.trayLoop(t)
+}
+
+func (a *App) onTrayExit() {
+	// Cleanup here
+}
+
+// refreshTray 请求刷新托盘菜单，短时间内的多次请求合并为一次
+func (a *App) refreshTray() {
+	select {
+	case a.trayUpdates <- struct{}{}:
+	default:
+	}
+}
+
+// trayLoop 处理托盘刷新请求
+func (a *App) trayLoop(t *trayMenu) {
+	for range a.trayUpdates {
+		a.updateTray(t)
+	}
+}
+
+// trayAction 记录托盘操作失败的原因
+func (a *App) trayAction(err error) {
+	if err != nil {
+		a.trayLogger().Warn("tray.actionFailed", "err", err)
+	}
+}
+
+// updateTray 按当前状态与语言刷新托盘图标和菜单
+func (a *App) updateTray(t *trayMenu) {
+	st := a.svc.Status()
+	cfg := a.svc.Config()
+	pause := a.svc.Paused()
+	clips := a.svc.RecentClips()
+
+	state := trayIdle
+	switch {
+	case st.HasError():
+		state = trayError
+	case st.ClientConnected || st.Clients > 0 || st.MeshPeers > 0:
+		state = trayConnected
+	}
+	if state != t.icon {
+		systray.SetIcon(t.icons[state])
+		t.icon = state
+	}
+
+	// 状态行
+	lines := trayStatusLines(&cfg, st)
+	for i, item := range []*systray.MenuItem{t.status, t.detail} {
+		if i < len(lines) {
+			item.SetTitle(lines[i])
+			item.Show()
+		} else {
+			item.Hide()
+		}
+	}
+	systray.SetTooltip(strings.Join(append([]string{"CCSync Net"}, lines...), "\n"))
+
+	setTrayTitle(t.show, i18n.T("tray.show"))
+
+	// 启动/停止与连接
+	switch {
+	case cfg.Mode == config.ModeMesh && st.MeshRunning:
+		setTrayTitle(t.server, i18n.T("tray.stopMesh"))
+	case cfg.Mode == config.ModeMesh:
+		setTrayTitle(t.server, i18n.T("tray.startMesh"))
+	case st.ServerRunning:
+		setTrayTitle(t.server, i18n.T("tray.stopServer"))
+	default:
+		setTrayTitle(t.server, i18n.T("tray.startServer"))
+	}
+	showTrayItem(t.server, cfg.RunsServer() || cfg.Mode == config.ModeMesh)
+
+	if st.ClientActive {
+		setTrayTitle(t.connect, i18n.T("tray.disconnect"))
+	} else {
+		setTrayTitle(t.connect, i18n.T("tray.connect"))
+	}
+	showTrayItem(t.connect, cfg.RunsClient())
+
+	// 暂停与同步方向
+	setTrayTitle(t.pause, i18n.T("tray.pause"))
+	setTrayChecked(t.pause, pause.Send || pause.Receive)
+	setTrayTitle(t.pause15, i18n.T("tray.pause15"))
+	setTrayTitle(t.push, i18n.T("tray.push"))
+	setTrayTitle(t.pull, i18n.T("tray.pull"))
+
+	setTrayTitle(t.syncMode, i18n.T("tray.syncMode"))
+	for mode, item := range t.syncModes {
+		setTrayTitle(item, i18n.T("tray.syncMode."+mode))
+		setTrayChecked(item, cfg.SyncMode == mode)
+	}
+
+	// 最近内容
+	setTrayTitle(t.recent, i18n.T("tray.recent"))
+	setTrayTitle(t.recentEmpty, i18n.T("tray.recentEmpty"))
+	showTrayItem(t.recentEmpty, len(clips) == 0)
+	for i, item := range t.recentItems {
+		if i >= len(clips) {
+			item.Hide()
+			continue
+		}
+		item.SetTitle(clipboard.Preview(clips[i].Content, trayPreviewLength))
+		if clips[i].Remote {
+			item.SetTooltip(i18n.T("tray.recentRemote", "source", clips[i].Source))
+		} else {
+			item.SetTooltip(i18n.T("tray.recentLocal"))
+		}
+		item.Show()
+	}
+
+	// 待确认内容
+	pending := a.svc.PendingClips()
+	setTrayTitle(t.pending, i18n.T("tray.pending", "count", len(pending)))
+	showTrayItem(t.pending, len(pending) > 0)
+	for i, item := range t.pendingItems {
+		if i >= len(pending) {
+			item.Hide()
+			continue
+		}
+		if pending[i].Sensitive {
+			item.SetTitle(i18n.T("tray.pendingHidden"))
+		} else {
+			item.SetTitle(clipboard.Preview(pending[i].Content, trayPreviewLength))
+		}
+		item.SetTooltip(i18n.T("tray.pendingItem", "sender", pending[i].Sender))
+		item.Show()
+	}
+	setTrayTitle(t.rejectAll, i18n.T("tray.rejectAll"))
+
+	setTrayTitle(t.quit, i18n.T("tray.quit"))
+}
+
+// trayStatusLines 生成托盘中显示的状态行
+func trayStatusLines(cfg *config.Config, st service.Status) []string {
+	var lines []string
+	switch {
+	case cfg.Mode == config.ModeMesh && st.MeshRunning:
+		lines = append(lines, i18n.T("tray.status.meshRunning", "peers", st.MeshPeers))
+	case cfg.Mode == config.ModeMesh:
+		lines = append(lines, i18n.T("tray.status.meshStopped"))
+	case !cfg.RunsServer():
+	case st.ServerRunning:
+		lines = append(lines, i18n.T("tray.status.serverRunning", "port", st.ServerPort, "clients", st.Clients))
+	case st.ServerError != "":
+		lines = append(lines, i18n.T("tray.status.serverError", "err", st.ServerError))
+	default:
+		lines = append(lines, i18n.T("tray.status.serverStopped"))
+	}
+
+	if cfg.RunsClient() {
+		switch {
+		case st.ClientConnected:
+			lines = append(lines, i18n.T("tray.status.connected", "endpoint", st.ClientEndpoint))
+		case st.ClientActive:
+			lines = append(lines, i18n.T("tray.status.reconnecting", "endpoint", st.ClientEndpoint))
+		default:
+			lines = append(lines, i18n.T("tray.status.disconnected"))
+		}
+	}
+	return lines
+}
+
+func setTrayTitle(item *systray.MenuItem, title string) {
+	item.SetTitle(title)
+	item.SetTooltip(title)
+}
+
+func setTrayChecked(item *systray.MenuItem, checked bool) {
+	if checked {
+		item.Check()
+	} else {
+		item.Uncheck()
+	}
+}
+
+func showTrayItem(item *systray.MenuItem, visible bool) {
+	if visible {
+		item.Show()
+	} else {
+		item.Hide()
+	}
+}
+
+func (a *App) trayLogger() *slog.Logger {
+	return logging.Component("tray")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+// trayState 托盘图标表示的状态
+type trayState int
+
+const (
+	trayIdle      trayState = iota // 未运行或没有连接
+	trayConnected                  // 已连接上游服务端或有客户端、节点连接
+	trayError                      // 服务端异常或连接断开正在重连
+)
+
+// trayDotColors 各状态在图标右下角显示的圆点颜色，空闲时不显示
+var trayDotColors = map[trayState]color.RGBA{
+	trayConnected: {R: 0x2e, G: 0xcc, B: 0x71, A: 0xff},
+	trayError:     {R: 0xe7, G: 0x4c, B: 0x3c, A: 0xff},
+}
+
+// trayIcons 生成各状态的托盘图标，生成失败时使用原图标
+func trayIcons() map[trayState][]byte {
+	icons := map[trayState][]byte{trayIdle: iconData}
+	for state, c := range trayDotColors {
+		icon, err := iconWithDot(iconPNG, c)
+		if err != nil {
+			icon = iconData
+		}
+		icons[state] = icon
+	}
+	return icons
+}
+
+// iconWithDot 在 PNG 图标右下角绘制带白边的圆点
+func iconWithDot(src []byte, c color.RGBA) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	dst := image.NewNRGBA(b)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+
+	size := min(b.Dx(), b.Dy())
+	r := size * 22 / 100
+	cx, cy := b.Max.X-r-size/32, b.Max.Y-r-size/32
+	border := max(r/5, 1)
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	for y := cy - r; y <= cy+r; y++ {
+		for x := cx - r; x <= cx+r; x++ {
+			d := (x-cx)*(x-cx) + (y-cy)*(y-cy)
+			switch {
+			case d <= (r-border)*(r-border):
+				dst.Set(x, y, c)
+			case d <= r*r:
+				dst.Set(x, y, white)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return encodeIcon(buf.Bytes()), nil
+}